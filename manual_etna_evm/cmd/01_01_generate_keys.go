@@ -6,12 +6,21 @@ import (
 	"strings"
 
 	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/spf13/cobra"
 )
 
+var (
+	encryptOwnerKey bool
+	useMnemonic     bool
+	splitRoles      bool
+)
+
 var GenerateKeysCmd = &cobra.Command{
 	Use:   "generate-keys",
 	Short: "Generate keys for the L1",
@@ -19,22 +28,177 @@ var GenerateKeysCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🔑 Generating keys")
 
-		keyExists, err := helpers.FileExists(helpers.ValidatorManagerOwnerKeyPath)
+		// The owner key follows the Ethereum path, so it can't also be the
+		// P-chain identity of a phrase
+		if useMnemonic && !splitRoles {
+			return fmt.Errorf("--mnemonic needs --split-roles, so the P-chain roles get keys on the Avalanche path instead of the Ethereum owner key")
+		}
+
+		keyExists, err := helpers.OwnerKeyProvider().Exists()
 		if err != nil {
 			return fmt.Errorf("failed to check if validator manager owner key exists: %w", err)
 		}
-		if !keyExists {
-			ownerKey, err := secp256k1.NewPrivateKey()
-			if err != nil {
-				return fmt.Errorf("failed to generate validator manager owner key: %w", err)
+		if useMnemonic {
+			if err := generateKeysFromMnemonic(keyExists); err != nil {
+				return err
+			}
+		} else {
+			if !keyExists {
+				ownerKey, err := secp256k1.NewPrivateKey()
+				if err != nil {
+					return fmt.Errorf("failed to generate validator manager owner key: %w", err)
+				}
+				if err := saveOwnerKey(ownerKey); err != nil {
+					return err
+				}
+			}
+			if splitRoles {
+				if err := generateRoleKeys(); err != nil {
+					return err
+				}
 			}
-			helpers.SaveSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath, ownerKey)
 		}
 
 		return GenerateCredsIfNotExists(helpers.Node0KeysFolder)
 	},
 }
 
+// generateRoleKeys gives every role that isn't held by anyone yet a random key
+func generateRoleKeys() error {
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	for _, role := range helpers.Roles {
+		exists, err := helpers.RoleKeyProvider(role).Exists()
+		if err != nil {
+			return fmt.Errorf("failed to check %s key: %w", role.Name, err)
+		}
+		if exists || manifest.Roles[role.Name] != "" {
+			continue
+		}
+		key, err := secp256k1.NewPrivateKey()
+		if err != nil {
+			return fmt.Errorf("failed to generate %s key: %w", role.Name, err)
+		}
+		if err := saveRoleKey(role, key, encryptOwnerKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveOwnerKey(ownerKey *secp256k1.PrivateKey) error {
+	provider := helpers.NewPlaintextKeyProvider(helpers.ValidatorManagerOwnerKeyPath)
+	if encryptOwnerKey {
+		provider = helpers.NewKeystoreKeyProvider(helpers.ValidatorManagerOwnerKeystorePath)
+	}
+	if err := provider.Save(ownerKey); err != nil {
+		return fmt.Errorf("failed to save validator manager owner key: %w", err)
+	}
+	log.Printf("Saved validator manager owner key to %s\n", provider.Path())
+	return nil
+}
+
+// generateKeysFromMnemonic derives the owner key and the key of every role
+// from an imported or new phrase. Existing keys are kept, but only if the
+// phrase derives them.
+func generateKeysFromMnemonic(ownerKeyExists bool) error {
+	mnemonic, err := helpers.ReadMnemonic()
+	if err != nil {
+		return fmt.Errorf("failed to read mnemonic: %w", err)
+	}
+	created := mnemonic == ""
+	if created {
+		if ownerKeyExists {
+			return fmt.Errorf("validator manager owner key already exists, import the mnemonic it was derived from with $%s", helpers.MnemonicEnvVar)
+		}
+		mnemonic, err = helpers.NewMnemonic()
+		if err != nil {
+			return fmt.Errorf("failed to generate mnemonic: %w", err)
+		}
+	}
+
+	ownerKey, err := helpers.DeriveHDKey(mnemonic, helpers.OwnerKeyRole)
+	if err != nil {
+		return fmt.Errorf("failed to derive validator manager owner key: %w", err)
+	}
+	if ownerKeyExists {
+		if err := checkDerivedKey(helpers.OwnerKeyProvider(), helpers.OwnerKeyRole, ownerKey); err != nil {
+			return err
+		}
+	} else if err := saveOwnerKey(ownerKey); err != nil {
+		return err
+	}
+	derived := []helpers.HDKeyRole{helpers.OwnerKeyRole}
+	keys := []*secp256k1.PrivateKey{ownerKey}
+
+	for _, role := range helpers.Roles {
+		key, err := helpers.DeriveHDKey(mnemonic, role.HD)
+		if err != nil {
+			return fmt.Errorf("failed to derive %s key: %w", role.Name, err)
+		}
+		saved, err := saveRoleKeyIfUnset(role, key)
+		if err != nil {
+			return err
+		}
+		if !saved {
+			if err := checkDerivedKey(helpers.RoleKeyProvider(role), role.HD, key); err != nil {
+				return err
+			}
+		}
+		derived = append(derived, role.HD)
+		keys = append(keys, key)
+	}
+
+	if created {
+		log.Println("⚠️  Write down this mnemonic, it is the only backup of the keys below and won't be shown again:")
+		log.Printf("\n\n%s\n\n", mnemonic)
+	}
+	hrp := constants.GetHRP(currentNetwork.NetworkID)
+	for i, role := range derived {
+		pChainAddr, err := address.Format("P", hrp, keys[i].Address().Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to format P-chain address: %w", err)
+		}
+		cChainAddr := crypto.PubkeyToAddress(keys[i].ToECDSA().PublicKey)
+		log.Printf("%s (%s): %s %s\n", role.Name, role.Path(), pChainAddr, cChainAddr.Hex())
+	}
+	return nil
+}
+
+// checkDerivedKey fails if the key already stored by provider isn't the one derived for role
+func checkDerivedKey(provider helpers.KeyProvider, role helpers.HDKeyRole, derived *secp256k1.PrivateKey) error {
+	existing, err := provider.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load %s key: %w", role.Name, err)
+	}
+	if existing.Address() != derived.Address() {
+		return fmt.Errorf("%s key %s was not derived from this mnemonic, which derives %s", role.Name, existing.Address(), derived.Address())
+	}
+	log.Printf("%s key matches the mnemonic\n", role.Name)
+	return nil
+}
+
+// saveRoleKeyIfUnset gives role its own key unless it already has a key or an address
+func saveRoleKeyIfUnset(role helpers.Role, key *secp256k1.PrivateKey) (bool, error) {
+	exists, err := helpers.RoleKeyProvider(role).Exists()
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s key: %w", role.Name, err)
+	}
+	if exists {
+		return false, nil
+	}
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return false, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if addr := manifest.Roles[role.Name]; addr != "" {
+		return false, fmt.Errorf("role %s is held by %s, run roles unset %s first", role.Name, addr, role.Name)
+	}
+	return true, saveRoleKey(role, key, encryptOwnerKey)
+}
+
 func PrintHeader(header string) {
 	log.Printf("\n\n%s\n\n", header)
 }
@@ -107,5 +271,8 @@ func generateSignerKey(folder string) error {
 }
 
 func init() {
+	GenerateKeysCmd.Flags().BoolVar(&encryptOwnerKey, "encrypt", false, fmt.Sprintf("Save the new keys in encrypted keystores, unlocked by a prompt or $%s", helpers.KeystorePassphraseEnvVar))
+	GenerateKeysCmd.Flags().BoolVar(&useMnemonic, "mnemonic", false, fmt.Sprintf("Derive the keys from a BIP-39 mnemonic, imported from $%s or a prompt, or created and printed once (needs --split-roles)", helpers.MnemonicEnvVar))
+	GenerateKeysCmd.Flags().BoolVar(&splitRoles, "split-roles", false, "Also give every role its own key instead of the validator manager owner key, see the roles command")
 	rootCmd.AddCommand(GenerateKeysCmd)
 }
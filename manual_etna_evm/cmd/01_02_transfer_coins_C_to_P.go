@@ -23,7 +23,6 @@ import (
 	"github.com/ava-labs/avalanchego/wallet/chain/p/wallet"
 	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
 	"github.com/ava-labs/coreth/ethclient"
-	"github.com/ava-labs/coreth/plugin/evm"
 )
 
 var TransferCoinsCmd = &cobra.Command{
@@ -33,13 +32,14 @@ var TransferCoinsCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("💰 Transferring AVAX between C and P chains")
 
-		key, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+		// Funds go to the P-chain address of the fee payer, from its own C-chain address
+		feePayer, err := roleSigner(helpers.RoleFeePayer)
 		if err != nil {
-			log.Fatalf("failed to load validator manager owner key: %s\n", err)
+			log.Fatalf("failed to load fee payer key: %s\n", err)
 		}
 
-		pChainAddr := key.Address()
-		cChainAddr := evm.PublicKeyToEthAddress(key.PublicKey())
+		pChainAddr := feePayer.Address()
+		cChainAddr := feePayer.EthAddress()
 
 		pChainBalance, err := CheckPChainBalance(context.Background(), pChainAddr)
 		if err != nil {
@@ -54,7 +54,7 @@ var TransferCoinsCmd = &cobra.Command{
 
 		log.Printf("P-chain balance insufficient on address %s: %s < %s\n", pChainAddr.String(), GetBalanceString(pChainBalance, 9), MIN_BALANCE_STRING)
 
-		cChainClient, err := ethclient.Dial(config.RPC_URL + "/ext/bc/C/rpc")
+		cChainClient, err := ethclient.Dial(currentNetwork.CChainURL)
 		if err != nil {
 			log.Fatalf("failed to connect to c-chain: %s\n", err)
 		}
@@ -71,9 +71,11 @@ var TransferCoinsCmd = &cobra.Command{
 
 		if cChainBalance.Uint64() < MIN_BALANCE {
 			log.Printf("Balance %s is less than minimum balance: %s\n", GetBalanceString(cChainBalance, 9), MIN_BALANCE_STRING)
-			log.Printf("Please visit https://test.core.app/tools/testnet-faucet/?subnet=c&token=c \n")
-			log.Printf("Use this address to request funds: %s\n", cChainAddr.Hex())
-			return fmt.Errorf("transfer to your Fuji C-chain address %s balance to at least %s AVAX", cChainAddr.Hex(), MIN_BALANCE_STRING)
+			if currentNetwork.Name == config.FujiNetwork {
+				log.Printf("Please visit https://test.core.app/tools/testnet-faucet/?subnet=c&token=c \n")
+				log.Printf("Use this address to request funds: %s\n", cChainAddr.Hex())
+			}
+			return fmt.Errorf("transfer to your %s C-chain address %s balance to at least %s AVAX", currentNetwork.Name, cChainAddr.Hex(), MIN_BALANCE_STRING)
 		} else {
 			log.Printf("C-chain balance sufficient: current %s, required %s\n", GetBalanceString(cChainBalance, 9), MIN_BALANCE_STRING)
 		}
@@ -81,9 +83,9 @@ var TransferCoinsCmd = &cobra.Command{
 		log.Printf("Transferring balance from C-chain to P-chain\n")
 
 		// Create keychain and wallet
-		kc := secp256k1fx.NewKeychain(key)
+		kc := helpers.NewSignerKeychain(feePayer)
 		wallet, err := primary.MakeWallet(context.Background(), &primary.WalletConfig{
-			URI:          config.RPC_URL,
+			URI:          currentNetwork.PChainURI,
 			AVAXKeychain: kc,
 			EthKeychain:  kc,
 		})
@@ -153,7 +155,7 @@ func CheckPChainBalance(ctx context.Context, addr ids.ShortID) (*big.Int, error)
 	addresses := set.Of(addr)
 
 	fetchStartTime := time.Now()
-	state, err := primary.FetchState(ctx, config.RPC_URL, addresses)
+	state, err := primary.FetchState(ctx, currentNetwork.PChainURI, addresses)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch state: %w", err)
 	}
@@ -1,21 +1,28 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
-	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+)
+
+var (
+	subnetOwnerAddrs []string
+	subnetThreshold  uint32
 )
 
 func init() {
+	CreateSubnetCmd.Flags().StringSliceVar(&subnetOwnerAddrs, "owners", nil, "P-chain addresses owning the subnet (defaults to the subnet-owner role)")
+	CreateSubnetCmd.Flags().Uint32Var(&subnetThreshold, "threshold", 1, "How many of the owners have to sign create-chain and convert-to-L1")
+	addUnsignedOutFlag(CreateSubnetCmd)
 	rootCmd.AddCommand(CreateSubnetCmd)
 }
 
@@ -26,58 +33,78 @@ var CreateSubnetCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🧱 Creating subnet")
 
-		exists, err := helpers.FileExists(helpers.SubnetIdPath)
+		manifest, err := helpers.LoadManifest()
 		if err != nil {
-			return fmt.Errorf("failed to check if subnet ID file exists: %w", err)
+			return fmt.Errorf("failed to load manifest: %w", err)
 		}
-		if exists {
-			log.Println("Subnet already exists, exiting")
+		if manifest.SubnetID != ids.Empty {
+			log.Printf("Subnet %s already exists, exiting\n", manifest.SubnetID)
 			return nil
 		}
 
 		// If we get here, we need to create a new subnet
-		key, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+		kc, _, err := pChainKeychain(helpers.RoleFeePayer)
 		if err != nil {
-			return fmt.Errorf("failed to load validator manager owner key: %w", err)
+			return err
+		}
+		owners, err := subnetOwnerAddresses()
+		if err != nil {
+			return err
+		}
+		if subnetThreshold == 0 || int(subnetThreshold) > len(owners) {
+			return fmt.Errorf("threshold must be between 1 and the %d owners, got %d", len(owners), subnetThreshold)
 		}
 
-		kc := secp256k1fx.NewKeychain(key)
-		subnetOwner := key.Address()
-
-		ctx := context.Background()
-
-		// MakeWallet fetches the available UTXOs owned by [kc] on the network that
-		// [uri] is hosting.
-		walletSyncStartTime := time.Now()
-		wallet, err := primary.MakeWallet(ctx, &primary.WalletConfig{
-			URI:          config.RPC_URL,
-			AVAXKeychain: kc,
-			EthKeychain:  kc,
-		})
+		wallet, err := pChainWallet(kc, nil, nil)
 		if err != nil {
-			log.Fatalf("❌ Failed to initialize wallet: %s\n", err)
+			return err
 		}
-		log.Printf("Synced wallet in %s\n", time.Since(walletSyncStartTime))
 
 		// Pull out useful constants to use when issuing transactions.
 		owner := &secp256k1fx.OutputOwners{
 			Locktime:  0,
-			Threshold: 1,
-			Addrs:     []ids.ShortID{subnetOwner},
+			Threshold: subnetThreshold,
+			Addrs:     owners,
 		}
+		owner.Sort()
 
 		createSubnetStartTime := time.Now()
-		createSubnetTx, err := wallet.P().IssueCreateSubnetTx(owner)
+		unsignedTx, err := wallet.Builder().NewCreateSubnetTx(owner)
+		if err != nil {
+			return fmt.Errorf("failed to build create subnet transaction: %w", err)
+		}
+		createSubnetTx, err := issuePChainTx(wallet, unsignedTx)
 		if err != nil {
 			log.Fatalf("❌ Failed to issue create subnet transaction: %s\n", err)
 		}
+		if createSubnetTx == nil {
+			return nil
+		}
 		log.Printf("✅ Created new subnet %s in %s\n", createSubnetTx.ID(), time.Since(createSubnetStartTime))
 
-		// Save the subnet ID to file
-		err = helpers.SaveId(helpers.SubnetIdPath, createSubnetTx.ID())
-		if err != nil {
-			return fmt.Errorf("failed to save subnet ID: %w", err)
+		if subnetThreshold > 1 {
+			log.Printf("%d of the %d owners will have to sign create-chain and convert-to-L1 with sign\n", subnetThreshold, len(owners))
 		}
-		return nil
+
+		// Record the subnet ID in the manifest
+		return recordPChainTx(createSubnetTx)
 	},
 }
+
+func subnetOwnerAddresses() ([]ids.ShortID, error) {
+	if len(subnetOwnerAddrs) == 0 {
+		owner, err := roleAddress(helpers.RoleSubnetOwner)
+		if err != nil {
+			return nil, err
+		}
+		return []ids.ShortID{owner}, nil
+	}
+	owners, err := address.ParseToIDs(subnetOwnerAddrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --owners: %w", err)
+	}
+	if set.Of(owners...).Len() != len(owners) {
+		return nil, fmt.Errorf("--owners lists the same address twice")
+	}
+	return owners, nil
+}
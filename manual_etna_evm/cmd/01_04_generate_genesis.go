@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	_ "embed"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -14,16 +12,13 @@ import (
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
 	"github.com/spf13/cobra"
 
-	_ "embed"
-
-	_ "embed"
-
 	"github.com/ava-labs/avalanche-cli/pkg/vm"
-	pluginEVM "github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/core"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -31,132 +26,296 @@ var (
 	defaultPoAOwnerBalance = new(big.Int).Mul(vm.OneAvax, big.NewInt(10)) // 10 Native Tokens
 )
 
-//go:embed proxy_compiled/deployed_proxy_admin_bytecode.txt
-var proxyAdminBytecodeHexString string
-
-//go:embed proxy_compiled/deployed_transparent_proxy_bytecode.txt
-var transparentProxyBytecodeHexString string
+var (
+	genesisSpecPath  string
+	managerInGenesis bool
+	genesisProxy     string
+)
 
 func init() {
+	GenerateGenesisCmd.Flags().BoolVar(&managerInGenesis, "manager-in-genesis", false, "Embed the --validator-type manager implementation in the genesis instead of deploying it after launch")
+	GenerateGenesisCmd.Flags().StringVar(&validatorType, "validator-type", "", fmt.Sprintf("Type of validator manager to embed with --manager-in-genesis (%s or %s)", config.PoAMode, config.PoSNativeMode))
+	GenerateGenesisCmd.Flags().StringArrayVar(&precompileAdmins, "precompile-admin", nil, "Make addresses admins of a precompile, enabling it: <precompile>=<address>[,<address>...]")
+	GenerateGenesisCmd.Flags().StringArrayVar(&precompileManagers, "precompile-manager", nil, "Make addresses managers of a precompile, enabling it: <precompile>=<address>[,<address>...]")
+	GenerateGenesisCmd.Flags().StringArrayVar(&precompileEnabled, "precompile-enabled", nil, "Allow addresses to use a precompile, enabling it: <precompile>=<address>[,<address>...]")
+	GenerateGenesisCmd.Flags().StringVar(&genesisProxy, "proxy", "", fmt.Sprintf("Proxy in front of the validator manager, overrides the spec (%s, default %s)", strings.Join(proxyFlavorNames(), " or "), config.ProxyOZ4Transparent))
+	GenerateGenesisCmd.Flags().StringVar(&genesisSpecPath, "spec", "", "YAML or JSON file overriding the chain ID, fee config, allocations, warp settings and timestamp (defaults to genesis-spec.yaml in the workspace data directory, if any)")
 	rootCmd.AddCommand(GenerateGenesisCmd)
 }
 
 var GenerateGenesisCmd = &cobra.Command{
-	Use:   "generate-genesis",
-	Short: "Generate genesis file for the L1",
-	Long:  `Generate genesis file for the L1`,
+	Use:         "generate-genesis",
+	Annotations: offline,
+	Short:       "Generate genesis file for the L1",
+	Long: `Generate genesis file for the L1
+
+The validator manager proxy and its proxy admin are always allocated. The
+rest has defaults a spec file can override:
+
+  chainID: 12345
+  proxy: oz5-transparent         # or oz4.9-transparent, the default
+  timestamp: 1735689600          # unix seconds, defaults to now
+  feeConfig:                     # any subnet-evm feeConfig fields
+    gasLimit: 15000000
+    minBaseFee: 1000000000
+  alloc:                         # subnet-evm alloc, balances in wei
+    "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC":
+      balance: 1000000000000000000000000
+  warp:
+    quorumNumerator: 67
+    requirePrimaryNetworkSigners: true
+  precompiles:                   # subnet-evm format of each precompile config
+    contractDeployerAllowList:
+      adminAddresses: ["0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"]
+    contractNativeMinter:
+      adminAddresses: ["0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"]
+      initialMint:
+        "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC": "0x3635c9adc5dea00000"
+
+Allocations can't overlap the proxies. An allocation to the owner or the
+manager owner replaces the 10 tokens they get by default.
+
+The precompiles are txAllowList, contractDeployerAllowList,
+contractNativeMinter, feeManager and rewardManager. They activate with the
+genesis unless their config sets blockTimestamp. The --precompile-* flags add
+addresses to their allow lists on top of the spec. The owner and the manager
+owner are enabled in txAllowList and contractDeployerAllowList if they would
+be locked out, since the later steps send transactions from them.
+
+By default the proxy points at the address the owner's second transaction
+deploys to, which deploy-validator-manager must then hit exactly. With
+--manager-in-genesis the --validator-type implementation, its
+ValidatorMessages library and, for PoS, the example reward calculator are
+placed at fixed addresses instead, so there is nothing to deploy.
+
+The proxy is OpenZeppelin 4.9 by default. oz5-transparent is the
+OpenZeppelin 5.0.2 proxy of the icm-contracts bindings, whose ProxyAdmin is
+created for that proxy alone. The runtime code of each proxy and admin is
+checked against a recorded keccak256 before the genesis is written.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🕸️  Generating genesis file")
-		ownerKey, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
-		if err != nil {
-			return fmt.Errorf("failed to load owner key: %s\n", err)
-		}
-
-		ethAddr := pluginEVM.PublicKeyToEthAddress(ownerKey.PublicKey())
-
-		now := time.Now().Unix()
-
-		feeConfig := commontype.FeeConfig{
-			GasLimit:                 big.NewInt(12000000),
-			TargetBlockRate:          2,
-			MinBaseFee:               big.NewInt(25000000000),
-			TargetGas:                big.NewInt(60000000),
-			BaseFeeChangeDenominator: big.NewInt(36),
-			MinBlockGasCost:          big.NewInt(0),
-			MaxBlockGasCost:          big.NewInt(1000000),
-			BlockGasCostStep:         big.NewInt(200000),
-		}
-
-		// zeroTime := uint64(0)
-
-		genesis := core.Genesis{
-			Config: &params.ChainConfig{
-				BerlinBlock:         big.NewInt(0),
-				ByzantiumBlock:      big.NewInt(0),
-				ConstantinopleBlock: big.NewInt(0),
-				EIP150Block:         big.NewInt(0),
-				EIP155Block:         big.NewInt(0),
-				EIP158Block:         big.NewInt(0),
-				HomesteadBlock:      big.NewInt(0),
-				IstanbulBlock:       big.NewInt(0),
-				LondonBlock:         big.NewInt(0),
-				MuirGlacierBlock:    big.NewInt(0),
-				PetersburgBlock:     big.NewInt(0),
-				FeeConfig:           feeConfig,
-				ChainID:             big.NewInt(config.L1_CHAIN_ID),
-			},
-			Alloc: types.GenesisAlloc{
-				ethAddr: {
-					Balance: defaultPoAOwnerBalance,
-				},
-			},
-			Difficulty: big.NewInt(0),
-			GasLimit:   uint64(12000000),
-			Timestamp:  uint64(now),
-		}
 
-		proxyAdminBytecodeHexString = strings.TrimSpace(strings.TrimPrefix(proxyAdminBytecodeHexString, "0x"))
-		transparentProxyBytecodeHexString = strings.TrimSpace(strings.TrimPrefix(transparentProxyBytecodeHexString, "0x"))
+		if managerInGenesis && validatorType == "" {
+			return fmt.Errorf("--manager-in-genesis needs --validator-type %s or %s", config.PoAMode, config.PoSNativeMode)
+		}
 
-		proxyAdminBytecode, err := hex.DecodeString(proxyAdminBytecodeHexString)
+		manifest, err := helpers.LoadManifest()
 		if err != nil {
-			return fmt.Errorf("failed to decode proxy admin bytecode: %s\n", err)
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		// The chain keeps the genesis it was created with, a new one would
+		// only disagree with it
+		if manifest.ChainID != ids.Empty {
+			return fmt.Errorf("chain %s was already created from %s, a new genesis needs a new chain: start over in a new workspace with `workspace create <name>`", manifest.ChainID, helpers.L1GenesisPath)
 		}
 
-		transparentProxyBytecode, err := hex.DecodeString(transparentProxyBytecodeHexString)
+		spec, err := loadGenesisSpec()
 		if err != nil {
-			return fmt.Errorf("failed to decode transparent proxy bytecode: %s\n", err)
+			return err
+		}
+		genesisJSON, err := buildL1Genesis(spec)
+		if err != nil {
+			return err
 		}
 
-		genesis.Alloc[common.HexToAddress(config.ProxyAdminContractAddress)] = types.Account{
-			Balance: big.NewInt(0),
-			Code:    proxyAdminBytecode,
-			Nonce:   1,
-			Storage: map[common.Hash]common.Hash{
-				common.HexToHash("0x0"): common.HexToHash(ethAddr.String()),
-			},
+		err = helpers.SaveText(helpers.L1GenesisPath, string(genesisJSON))
+		if err != nil {
+			return fmt.Errorf("failed to save genesis: %s\n", err)
 		}
 
-		genesis.Alloc[common.HexToAddress(config.ProxyContractAddress)] = types.Account{
-			Balance: big.NewInt(0),
-			Code:    transparentProxyBytecode,
-			Nonce:   1,
-			Storage: map[common.Hash]common.Hash{
-				common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"): common.HexToHash(MustDeriveContractAddress(ethAddr, 1).String()),
-				common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103"): common.HexToHash(config.ProxyAdminContractAddress),
-			},
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			manifest.ManagerInGenesis = managerInGenesis
+			if managerInGenesis {
+				manifest.ValidatorType = validatorType
+				manifest.ExampleRewardCalculatorAddress = common.Address{}
+				if validatorType == config.PoSNativeMode {
+					manifest.ExampleRewardCalculatorAddress = common.HexToAddress(config.RewardCalculatorAddress)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record validator manager in manifest: %w", err)
 		}
 
-		// Convert genesis to map to add warpConfig
-		genesisMap := make(map[string]interface{})
-		genesisBytes, err := json.Marshal(genesis)
+		log.Printf("Successfully wrote genesis to %s\n", helpers.L1GenesisPath)
+
+		return nil
+	},
+}
+
+// loadGenesisSpec loads --spec, or the spec of the workspace. Without either
+// every default applies.
+func loadGenesisSpec() (*helpers.GenesisSpec, error) {
+	path := genesisSpecPath
+	if path == "" {
+		exists, err := helpers.FileExists(helpers.GenesisSpecPath)
 		if err != nil {
-			return fmt.Errorf("failed to marshal genesis to map: %s\n", err)
+			return nil, err
 		}
-		if err := json.Unmarshal(genesisBytes, &genesisMap); err != nil {
-			return fmt.Errorf("failed to unmarshal genesis to map: %s\n", err)
+		if !exists {
+			return &helpers.GenesisSpec{}, nil
 		}
+		path = helpers.GenesisSpecPath
+	}
+	spec, err := helpers.LoadGenesisSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using genesis spec %s\n", path)
+	return spec, nil
+}
+
+// buildL1Genesis merges spec with the defaults and the validator manager
+// proxies, and returns the genesis JSON
+func buildL1Genesis(spec *helpers.GenesisSpec) ([]byte, error) {
+	owner, err := ownerAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner key deploys the validator manager unless it is in the
+	// genesis, the proxy admin owner can upgrade it and the manager owner
+	// calls it
+	ethAddr := owner.EthAddress
+	proxyAdminOwner, err := roleEthAddress(helpers.RoleProxyAdminOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy admin owner: %w", err)
+	}
+	managerOwner, err := roleEthAddress(helpers.RoleManagerOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manager owner: %w", err)
+	}
 
-		// Add warpConfig to config
-		configMap := genesisMap["config"].(map[string]interface{})
-		configMap["warpConfig"] = map[string]interface{}{
-			"blockTimestamp":               now,
-			"quorumNumerator":              67,
-			"requirePrimaryNetworkSigners": true,
+	timestamp := uint64(time.Now().Unix())
+	if spec.Timestamp != nil {
+		timestamp = *spec.Timestamp
+	}
+
+	chainID := uint64(config.L1_CHAIN_ID)
+	if spec.ChainID != nil {
+		chainID = *spec.ChainID
+	}
+	if chainID == 0 {
+		return nil, fmt.Errorf("chain ID can't be 0")
+	}
+
+	feeConfig := commontype.FeeConfig{
+		GasLimit:                 big.NewInt(12000000),
+		TargetBlockRate:          2,
+		MinBaseFee:               big.NewInt(25000000000),
+		TargetGas:                big.NewInt(60000000),
+		BaseFeeChangeDenominator: big.NewInt(36),
+		MinBlockGasCost:          big.NewInt(0),
+		MaxBlockGasCost:          big.NewInt(1000000),
+		BlockGasCostStep:         big.NewInt(200000),
+	}
+	if len(spec.FeeConfig) > 0 {
+		if err := helpers.DecodeStrictJSON(spec.FeeConfig, &feeConfig); err != nil {
+			return nil, fmt.Errorf("invalid feeConfig in genesis spec: %w", err)
 		}
+	}
+	if err := feeConfig.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid feeConfig in genesis spec: %w", err)
+	}
 
-		prettyJSON, err := json.MarshalIndent(genesisMap, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal genesis: %s\n", err)
+	quorumNumerator := warp.WarpDefaultQuorumNumerator
+	requirePrimaryNetworkSigners := true
+	if spec.Warp != nil {
+		if spec.Warp.QuorumNumerator != nil {
+			quorumNumerator = *spec.Warp.QuorumNumerator
 		}
+		if spec.Warp.RequirePrimaryNetworkSigners != nil {
+			requirePrimaryNetworkSigners = *spec.Warp.RequirePrimaryNetworkSigners
+		}
+	}
+	if quorumNumerator < warp.WarpQuorumNumeratorMinimum || quorumNumerator > warp.WarpQuorumDenominator {
+		return nil, fmt.Errorf("warp quorum numerator must be between %d and %d, got %d", warp.WarpQuorumNumeratorMinimum, warp.WarpQuorumDenominator, quorumNumerator)
+	}
+
+	precompiles, err := genesisPrecompiles(spec, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	allowSetupAccounts(precompiles, ethAddr, managerOwner)
+	precompiles[warp.ConfigKey] = warp.NewConfig(&timestamp, quorumNumerator, requirePrimaryNetworkSigners)
+
+	genesis := core.Genesis{
+		Config: &params.ChainConfig{
+			BerlinBlock:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			HomesteadBlock:      big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			LondonBlock:         big.NewInt(0),
+			MuirGlacierBlock:    big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			FeeConfig:           feeConfig,
+			ChainID:             new(big.Int).SetUint64(chainID),
+			GenesisPrecompiles:  precompiles,
+		},
+		Alloc: types.GenesisAlloc{
+			ethAddr: {
+				Balance: defaultPoAOwnerBalance,
+			},
+		},
+		Difficulty: big.NewInt(0),
+		GasLimit:   feeConfig.GasLimit.Uint64(),
+		Timestamp:  timestamp,
+	}
+	if managerOwner != ethAddr {
+		genesis.Alloc[managerOwner] = types.Account{
+			Balance: defaultPoAOwnerBalance,
+		}
+	}
+
+	implementation := MustDeriveContractAddress(ethAddr, 1)
+	if managerInGenesis {
+		implementation = common.HexToAddress(config.ValidatorManagerImplementationAddress)
+	}
 
-		err = helpers.SaveText(helpers.L1GenesisPath, string(prettyJSON))
+	proxy := config.ProxyOZ4Transparent
+	if spec.Proxy != "" {
+		proxy = spec.Proxy
+	}
+	if genesisProxy != "" {
+		proxy = genesisProxy
+	}
+	required, err := genesisProxyAlloc(proxy, implementation, proxyAdminOwner)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using the %s proxy from %s\n", proxy, proxyFlavors[proxy].source)
+	if managerInGenesis {
+		managerAlloc, err := genesisValidatorManagerAlloc(validatorType)
 		if err != nil {
-			return fmt.Errorf("failed to save genesis: %s\n", err)
+			return nil, err
+		}
+		for addr, account := range managerAlloc {
+			required[addr] = account
+		}
+		log.Printf("Embedding the %s validator manager at %s\n", validatorType, implementation)
+	}
+	for addr, account := range spec.Alloc {
+		if _, ok := required[addr]; ok {
+			return nil, fmt.Errorf("genesis spec allocates %s, which is reserved for the validator manager", addr)
 		}
+		genesis.Alloc[addr] = account
+	}
+	for addr, account := range required {
+		genesis.Alloc[addr] = account
+	}
 
-		log.Printf("Successfully wrote genesis to data/L1-genesis.json\n")
+	if err := verifyGenesisPrecompiles(genesis.Config); err != nil {
+		return nil, err
+	}
 
-		return nil
-	},
+	prettyJSON, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal genesis: %w", err)
+	}
+	return prettyJSON, nil
 }
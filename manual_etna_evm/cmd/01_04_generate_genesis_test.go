@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testGenesis builds the genesis of spec in a temporary workspace holding
+// only an owner key
+func testGenesis(t *testing.T, spec *helpers.GenesisSpec) (*core.Genesis, error) {
+	t.Helper()
+	inTempDir(t)
+	key, err := secp256k1.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helpers.SaveSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath, key); err != nil {
+		t.Fatal(err)
+	}
+	genesisJSON, err := buildL1Genesis(spec)
+	if err != nil {
+		return nil, err
+	}
+	genesis := &core.Genesis{}
+	if err := json.Unmarshal(genesisJSON, genesis); err != nil {
+		t.Fatalf("failed to parse the genesis: %s", err)
+	}
+	return genesis, nil
+}
+
+func TestBuildL1GenesisMergesSpec(t *testing.T) {
+	chainID := uint64(4321)
+	quorum := uint64(80)
+	extra := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	spec := &helpers.GenesisSpec{
+		ChainID:   &chainID,
+		FeeConfig: json.RawMessage(`{"minBaseFee": 1}`),
+		Alloc:     types.GenesisAlloc{extra: {Balance: big.NewInt(42)}},
+		Warp:      &helpers.GenesisWarpSpec{QuorumNumerator: &quorum},
+	}
+	genesis, err := testGenesis(t, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if genesis.Config.ChainID.Uint64() != chainID {
+		t.Fatalf("chain ID is %s, want %d", genesis.Config.ChainID, chainID)
+	}
+	feeConfig := genesis.Config.FeeConfig
+	if feeConfig.MinBaseFee.Int64() != 1 {
+		t.Fatalf("min base fee is %s, want the spec's 1", feeConfig.MinBaseFee)
+	}
+	if feeConfig.GasLimit.Int64() != 12000000 || genesis.GasLimit != 12000000 {
+		t.Fatalf("gas limit is %s (block %d), want the default 12000000", feeConfig.GasLimit, genesis.GasLimit)
+	}
+	if account, ok := genesis.Alloc[extra]; !ok || account.Balance.Int64() != 42 {
+		t.Fatalf("spec alloc of %s is %+v", extra, genesis.Alloc[extra])
+	}
+	for _, reserved := range []string{config.ProxyContractAddress, config.ProxyAdminContractAddress} {
+		if _, ok := genesis.Alloc[common.HexToAddress(reserved)]; !ok {
+			t.Fatalf("proxy account %s is missing", reserved)
+		}
+	}
+	warpConfig, ok := genesis.Config.GenesisPrecompiles[warp.ConfigKey].(*warp.Config)
+	if !ok || warpConfig.QuorumNumerator != quorum {
+		t.Fatalf("warp config is %+v, want a quorum numerator of %d", genesis.Config.GenesisPrecompiles[warp.ConfigKey], quorum)
+	}
+}
+
+func TestBuildL1GenesisRejectsConflicts(t *testing.T) {
+	zero := uint64(0)
+	tooLowQuorum := uint64(1)
+	tests := []struct {
+		name string
+		spec *helpers.GenesisSpec
+		want string
+	}{
+		{"proxy allocation", &helpers.GenesisSpec{Alloc: types.GenesisAlloc{common.HexToAddress(config.ProxyContractAddress): {Balance: big.NewInt(1)}}}, "reserved"},
+		{"proxy admin allocation", &helpers.GenesisSpec{Alloc: types.GenesisAlloc{common.HexToAddress(config.ProxyAdminContractAddress): {Balance: big.NewInt(1)}}}, "reserved"},
+		{"unknown fee config field", &helpers.GenesisSpec{FeeConfig: json.RawMessage(`{"minBaseFees": 1}`)}, "feeConfig"},
+		{"invalid fee config", &helpers.GenesisSpec{FeeConfig: json.RawMessage(`{"gasLimit": 0}`)}, "feeConfig"},
+		{"chain ID 0", &helpers.GenesisSpec{ChainID: &zero}, "chain ID"},
+		{"warp quorum", &helpers.GenesisSpec{Warp: &helpers.GenesisWarpSpec{QuorumNumerator: &tooLowQuorum}}, "quorum"},
+		{"UUPS proxy", &helpers.GenesisSpec{Proxy: config.ProxyUUPS}, "UUPS"},
+		{"unknown proxy", &helpers.GenesisSpec{Proxy: "beacon"}, "unknown proxy"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := testGenesis(t, test.spec)
+			if err == nil {
+				t.Fatal("the spec was accepted")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("error %q doesn't mention %q", err, test.want)
+			}
+		})
+	}
+}
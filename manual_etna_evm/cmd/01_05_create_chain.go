@@ -1,22 +1,21 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
-	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
-	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
 )
 
 func init() {
+	addUnsignedOutFlag(CreateChainCmd)
 	rootCmd.AddCommand(CreateChainCmd)
 }
 
@@ -27,22 +26,21 @@ var CreateChainCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🧱 Creating chain")
 
-		exists, err := helpers.FileExists(helpers.ChainIdPath)
+		manifest, err := helpers.LoadManifest()
 		if err != nil {
-			return fmt.Errorf("failed to check if chain ID file exists: %w", err)
+			return fmt.Errorf("failed to load manifest: %w", err)
 		}
-		if exists {
-			log.Println("Chain already exists, exiting")
+		if manifest.ChainID != ids.Empty {
+			log.Printf("Chain %s already exists, exiting\n", manifest.ChainID)
 			return nil
 		}
 
-		key, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+		kc, feePayer, err := pChainKeychain(helpers.RoleFeePayer, helpers.RoleSubnetOwner)
 		if err != nil {
-			return fmt.Errorf("failed to load validator manager owner key: %w", err)
+			return err
 		}
-		kc := secp256k1fx.NewKeychain(key)
 
-		subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+		subnetID, err := helpers.LoadSubnetID()
 		if err != nil {
 			return fmt.Errorf("failed to load subnet ID: %w", err)
 		}
@@ -54,45 +52,43 @@ var CreateChainCmd = &cobra.Command{
 			return fmt.Errorf("failed to load genesis: %w", err)
 		}
 
-		ctx := context.Background()
-
-		// MakeWallet fetches the available UTXOs owned by [kc] on the network that
-		// [uri] is hosting and registers [subnetID].
-		walletSyncStartTime := time.Now()
-		wallet, err := primary.MakeWallet(ctx, &primary.WalletConfig{
-			URI:          config.RPC_URL,
-			AVAXKeychain: kc,
-			EthKeychain:  kc,
-			SubnetIDs:    []ids.ID{subnetID},
-		})
+		// The wallet fetches the available UTXOs owned by [kc] and the owners of
+		// [subnetID]
+		wallet, err := pChainWallet(kc, []ids.ID{subnetID}, nil)
 		if err != nil {
-			return fmt.Errorf("failed to initialize wallet: %w", err)
+			return err
 		}
-		log.Printf("synced wallet in %s\n", time.Since(walletSyncStartTime))
-
-		// Get the P-chain wallet
-		pWallet := wallet.P()
 
 		createChainStartTime := time.Now()
-		createChainTx, err := pWallet.IssueCreateChainTx(
-			subnetID,
-			[]byte(genesisString),
-			constants.SubnetEVMID,
-			nil,
-			"My L1",
-		)
+		validate := func(tx *txs.Tx) error {
+			createChainTx, ok := tx.Unsigned.(*txs.CreateChainTx)
+			if !ok {
+				return fmt.Errorf("expected a create chain tx, got %T", tx.Unsigned)
+			}
+			if createChainTx.SubnetID != subnetID || string(createChainTx.GenesisData) != genesisString {
+				return fmt.Errorf("tx creates a chain on subnet %s with another genesis", createChainTx.SubnetID)
+			}
+			return nil
+		}
+		createChainTx, err := issueSubnetAuthTx(wallet, kc, feePayer, subnetID, helpers.PendingTxPath("create_chain"), validate, func(options ...common.Option) (txs.UnsignedTx, error) {
+			return wallet.Builder().NewCreateChainTx(
+				subnetID,
+				[]byte(genesisString),
+				constants.SubnetEVMID,
+				nil,
+				"My L1",
+				options...,
+			)
+		})
 		if err != nil {
 			return fmt.Errorf("failed to issue create chain transaction: %w", err)
 		}
-		log.Printf("Created new chain %s in %s\n", createChainTx.ID(), time.Since(createChainStartTime))
-
-		// Save the chain ID to file
-		err = helpers.SaveId(helpers.ChainIdPath, createChainTx.ID())
-		if err != nil {
-			return fmt.Errorf("failed to save chain ID: %w", err)
+		if createChainTx == nil {
+			return nil
 		}
+		log.Printf("Created new chain %s in %s\n", createChainTx.ID(), time.Since(createChainStartTime))
 
-		log.Println("Saved chain ID to file")
-		return nil
+		// Record the chain ID in the manifest
+		return recordPChainTx(createChainTx)
 	},
 }
@@ -1,26 +1,19 @@
 package cmd
 
 import (
-	"context"
-	"encoding/hex"
+	"bytes"
 	"encoding/pem"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
-	"github.com/ava-labs/avalanche-cli/cmd/blockchaincmd"
-	"github.com/ava-labs/avalanche-cli/pkg/constants"
-	"github.com/ava-labs/avalanche-cli/pkg/key"
-	"github.com/ava-labs/avalanche-cli/pkg/models"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/staking"
-	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
-	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
-	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
 	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
@@ -28,138 +21,175 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var convertValidatorBalance string
+
 func init() {
+	ConvertToL1Cmd.Flags().StringVar(&convertValidatorBalance, "balance", "1", "AVAX each bootstrap validator gets to pay the continuous fee, like 1.5")
+	ConvertToL1Cmd.Flags().StringArrayVar(&convertBootstrapValidators, "bootstrap-validator", nil, "Bootstrap validator as <creds folder|http(s)://node>[,weight=N][,balance=AVAX][,change-owner=P-...][,uri=http(s)://node], repeat for each (default node0)")
+	addUnsignedOutFlag(ConvertToL1Cmd)
 	rootCmd.AddCommand(ConvertToL1Cmd)
 }
 
 var ConvertToL1Cmd = &cobra.Command{
 	Use:   "convert-to-L1",
 	Short: "Convert the subnet to L1",
-	Long:  `Convert the subnet to L1`,
+	Long: `Convert the subnet to L1.
+
+Each --bootstrap-validator is a folder with staker.crt and signer.key, or the
+URI of a running node asked through info.getNodeID. Weight, balance and the
+P-chain address getting the remaining balance (change-owner) can be set per
+validator. A creds folder of a node running elsewhere takes uri= so the
+signature aggregator can reach it. Without the flag node0 is the only
+bootstrap validator, which leaves the L1 without fault tolerance.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🔌 Converting subnet to L1")
 
-		exists, err := helpers.FileExists(helpers.ConversionIdPath)
+		manifest, err := helpers.LoadManifest()
 		if err != nil {
-			return fmt.Errorf("failed to check if conversion ID file exists: %w", err)
+			return fmt.Errorf("failed to load manifest: %w", err)
 		}
 
-		if exists {
+		if manifest.ConversionTxID != ids.Empty {
 			log.Println("✅ Subnet was already converted to L1")
 			return nil
 		}
 
-		chainID, err := helpers.LoadId(helpers.ChainIdPath)
+		balance, err := validatorBalance(convertValidatorBalance)
 		if err != nil {
-			return fmt.Errorf("failed to load chain ID: %w", err)
+			return err
 		}
 
-		privKey, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+		chainID, err := helpers.LoadChainID()
 		if err != nil {
-			return fmt.Errorf("failed to load validator manager owner key: %w", err)
+			return fmt.Errorf("failed to load chain ID: %w", err)
 		}
-		kc := secp256k1fx.NewKeychain(privKey)
 
-		subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+		kc, feePayer, err := pChainKeychain(helpers.RoleFeePayer, helpers.RoleSubnetOwner)
 		if err != nil {
-			return fmt.Errorf("failed to load subnet ID: %w", err)
+			return err
 		}
 
-		wallet, err := primary.MakeWallet(context.Background(), &primary.WalletConfig{
-			URI:          config.RPC_URL,
-			AVAXKeychain: kc,
-			EthKeychain:  kc,
-			SubnetIDs:    []ids.ID{subnetID},
-		})
+		subnetID, err := helpers.LoadSubnetID()
 		if err != nil {
-			return fmt.Errorf("❌ Failed to initialize wallet: %w", err)
+			return fmt.Errorf("failed to load subnet ID: %w", err)
 		}
 
-		//TODO: replace with address.Format
-		softKey, err := key.NewSoft(avagoconstants.TestnetID, key.WithPrivateKey(privKey))
+		wallet, err := pChainWallet(kc, []ids.ID{subnetID}, nil)
 		if err != nil {
-			return fmt.Errorf("❌ Failed to create change owner address: %w", err)
+			return err
 		}
 
-		changeOwnerAddress := softKey.P()[0]
-
-		fmt.Printf("Using changeOwnerAddress: %s\n", changeOwnerAddress)
-
-		subnetAuthKeys, err := address.ParseToIDs([]string{changeOwnerAddress})
+		// The bootstrap validators get the same owners as the validators added
+		// later, unless a change owner is given
+		balanceOwner, err := roleAddress(helpers.RoleValidatorBalanceOwner)
 		if err != nil {
-			return fmt.Errorf("❌ Failed to parse subnet auth keys: %w", err)
+			return err
 		}
-
-		validators := []models.SubnetValidator{}
-
-		nodeID, proofOfPossession, err := NodeInfoFromCreds(helpers.Node0KeysFolder)
+		disableOwner, err := roleAddress(helpers.RoleValidatorDisableOwner)
 		if err != nil {
-			return fmt.Errorf("failed to get node info from creds: %w", err)
+			return err
 		}
 
-		publicKey := "0x" + hex.EncodeToString(proofOfPossession.PublicKey[:])
-		pop := "0x" + hex.EncodeToString(proofOfPossession.ProofOfPossession[:])
-
-		validator := models.SubnetValidator{
-			NodeID:               nodeID.String(),
-			Weight:               constants.BootstrapValidatorWeight,
-			Balance:              constants.BootstrapValidatorBalance,
-			BLSPublicKey:         publicKey,
-			BLSProofOfPossession: pop,
-			ChangeOwnerAddr:      changeOwnerAddress,
+		specs, err := parseBootstrapValidators(convertBootstrapValidators, balance)
+		if err != nil {
+			return err
 		}
-		validators = append(validators, validator)
-
-		avaGoBootstrapValidators, err := blockchaincmd.ConvertToAvalancheGoSubnetValidator(validators)
+		avaGoBootstrapValidators, sources, err := convertValidators(specs, balanceOwner, disableOwner)
 		if err != nil {
-			return fmt.Errorf("❌ Failed to convert to AvalancheGo subnet validator: %w", err)
+			return fmt.Errorf("❌ Failed to get bootstrap validators: %w", err)
 		}
 
 		managerAddress := goethereumcommon.HexToAddress(config.ProxyContractAddress)
-		options := getMultisigTxOptions(subnetAuthKeys, kc)
 
 		convertLog := fmt.Sprintf("Issuing convert subnet tx\n"+
 			"subnetID: %s\n"+
 			"chainID: %s\n"+
-			"managerAddress: %x\n"+
-			"avaGoBootstrapValidators[0]:\n"+
-			"\tNodeID: %x\n"+
-			"\tBLS Public Key: %x\n"+
-			"\tWeight: %d\n"+
-			"\tBalance: %d\n",
+			"managerAddress: %x\n",
 			subnetID.String(),
 			chainID.String(),
 			managerAddress[:],
-			avaGoBootstrapValidators[0].NodeID[:],
-			avaGoBootstrapValidators[0].Signer.PublicKey[:],
-			int(avaGoBootstrapValidators[0].Weight),
-			int(avaGoBootstrapValidators[0].Balance),
 		)
+		for i, validator := range avaGoBootstrapValidators {
+			changeOwnerAddress, err := formatPChainAddress(validator.RemainingBalanceOwner.Addresses[0])
+			if err != nil {
+				return fmt.Errorf("❌ Failed to format change owner address: %w", err)
+			}
+			convertLog += fmt.Sprintf("avaGoBootstrapValidators[%d]:\n"+
+				"\tNodeID: %x\n"+
+				"\tBLS Public Key: %x\n"+
+				"\tWeight: %d\n"+
+				"\tBalance: %d\n"+
+				"\tChange owner: %s\n",
+				i,
+				validator.NodeID[:],
+				validator.Signer.PublicKey[:],
+				validator.Weight,
+				validator.Balance,
+				changeOwnerAddress,
+			)
+		}
 
 		log.Println(convertLog)
-		err = os.WriteFile("./data/convert_log.txt", []byte(convertLog), 0644)
+		err = helpers.SaveText(helpers.DataDir+"convert_log.txt", convertLog)
 		if err != nil {
 			return fmt.Errorf("❌ Failed to write convert log: %w", err)
 		}
 
-		if len(avaGoBootstrapValidators) > 1 {
-			fmt.Printf("⚠️ WARNING! Only the first validator's info is printed\n")
+		validate := func(tx *txs.Tx) error {
+			convertTx, ok := tx.Unsigned.(*txs.ConvertSubnetToL1Tx)
+			if !ok {
+				return fmt.Errorf("expected a convert subnet to L1 tx, got %T", tx.Unsigned)
+			}
+			if convertTx.Subnet != subnetID || convertTx.ChainID != chainID || !bytes.Equal(convertTx.Address, managerAddress.Bytes()) {
+				return fmt.Errorf("tx converts subnet %s with manager %x on chain %s", convertTx.Subnet, convertTx.Address, convertTx.ChainID)
+			}
+			if len(convertTx.Validators) != len(avaGoBootstrapValidators) {
+				return fmt.Errorf("tx has %d bootstrap validators, expected %d", len(convertTx.Validators), len(avaGoBootstrapValidators))
+			}
+			for i, validator := range convertTx.Validators {
+				expected := avaGoBootstrapValidators[i]
+				if !bytes.Equal(validator.NodeID, expected.NodeID) || validator.Weight != expected.Weight || validator.Balance != expected.Balance {
+					return fmt.Errorf("bootstrap validator %d of the tx is %x with weight %d and balance %d, expected %x with weight %d and balance %d", i, validator.NodeID, validator.Weight, validator.Balance, expected.NodeID, expected.Weight, expected.Balance)
+				}
+			}
+			return nil
 		}
-
-		tx, err := wallet.P().IssueConvertSubnetToL1Tx(
-			subnetID,
-			chainID,
-			managerAddress.Bytes(),
-			avaGoBootstrapValidators,
-			options...,
-		)
+		tx, err := issueSubnetAuthTx(wallet, kc, feePayer, subnetID, helpers.PendingTxPath("convert_to_L1"), validate, func(options ...common.Option) (txs.UnsignedTx, error) {
+			return wallet.Builder().NewConvertSubnetToL1Tx(
+				subnetID,
+				chainID,
+				managerAddress.Bytes(),
+				avaGoBootstrapValidators,
+				options...,
+			)
+		})
 		if err != nil {
 			return fmt.Errorf("❌ Failed to create convert subnet tx: %w", err)
 		}
 
-		err = helpers.SaveId(helpers.ConversionIdPath, tx.ID())
+		if tx == nil {
+			return nil
+		}
+		if err := recordPChainTx(tx); err != nil {
+			return err
+		}
+		// Remember where each bootstrap validator runs, for the signature aggregator
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			for nodeID, spec := range sources {
+				validator, ok := manifest.Validator(nodeID)
+				if !ok {
+					continue
+				}
+				if spec.credsFolder != "" {
+					validator.CredsFolder = spec.credsFolder
+				}
+				validator.NodeURI = spec.uri
+				manifest.SetValidator(validator)
+			}
+			return nil
+		})
 		if err != nil {
-			return fmt.Errorf("failed to save conversion ID: %w", err)
+			return fmt.Errorf("failed to record bootstrap validators: %w", err)
 		}
 
 		log.Printf("✅ Convert subnet tx ID: %s\n", tx.ID().String())
@@ -167,16 +197,15 @@ var ConvertToL1Cmd = &cobra.Command{
 	},
 }
 
-func getMultisigTxOptions(subnetAuthKeys []ids.ShortID, kc *secp256k1fx.Keychain) []common.Option {
+func getMultisigTxOptions(subnetAuthKeys []ids.ShortID, changeAddr ids.ShortID, kc keychain.Keychain) []common.Option {
 	options := []common.Option{}
 	walletAddrs := kc.Addresses().List()
-	changeAddr := walletAddrs[0]
 	// addrs to use for signing
 	customAddrsSet := set.Set[ids.ShortID]{}
 	customAddrsSet.Add(walletAddrs...)
 	customAddrsSet.Add(subnetAuthKeys...)
 	options = append(options, common.WithCustomAddresses(customAddrsSet))
-	// set change to go to wallet addr (instead of any other subnet auth key)
+	// set change to go to the fee payer (instead of any other subnet auth key)
 	changeOwner := &secp256k1fx.OutputOwners{
 		Threshold: 1,
 		Addrs:     []ids.ShortID{changeAddr},
@@ -203,12 +232,12 @@ func NodeInfoFromCreds(folder string) (ids.NodeID, *signer.ProofOfPossession, er
 
 	block, _ := pem.Decode([]byte(certString))
 	if block == nil || block.Type != "CERTIFICATE" {
-		panic("failed to decode PEM block containing certificate")
+		return ids.NodeID{}, nil, fmt.Errorf("failed to decode PEM block containing certificate in %s", folder)
 	}
 
 	cert, err := staking.ParseCertificate(block.Bytes)
 	if err != nil {
-		panic("failed to decode PEM block containing certificate")
+		return ids.NodeID{}, nil, fmt.Errorf("failed to decode certificate in %s: %w", folder, err)
 	}
 
 	nodeID := ids.NodeIDFromCert(cert)
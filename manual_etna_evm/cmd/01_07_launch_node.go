@@ -7,6 +7,8 @@ import (
 	"math/big"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -40,18 +42,18 @@ var launchNodeCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🐳 Launching node (might take up to 5 minutes)")
 
-		subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+		subnetID, err := helpers.LoadSubnetID()
 		if err != nil {
 			return fmt.Errorf("failed to load subnet ID: %w", err)
 		}
 
-		chainID, err := helpers.LoadId(helpers.ChainIdPath)
+		chainID, err := helpers.LoadChainID()
 		if err != nil {
 			return fmt.Errorf("failed to load chain ID: %w", err)
 		}
 
 		// Create chains directory if it doesn't exist
-		chainsDir := fmt.Sprintf("./data/chains/%s", chainID)
+		chainsDir := filepath.Join(helpers.DataDir, "chains", chainID.String())
 		err = os.MkdirAll(chainsDir, 0755)
 		if err != nil {
 			return fmt.Errorf("failed to create chains directory: %w", err)
@@ -76,15 +78,31 @@ var launchNodeCmd = &cobra.Command{
 			return fmt.Errorf("failed to get current GID: %w", err)
 		}
 
+		dataDir, err := filepath.Abs(helpers.DataDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve data directory: %w", err)
+		}
+
+		httpPort, err := helpers.NodeHTTPPort(0)
+		if err != nil {
+			return fmt.Errorf("failed to get node0 port: %w", err)
+		}
+
 		// Set environment variables
 		env := []string{
 			fmt.Sprintf("CURRENT_UID=%s", strings.TrimSpace(string(uidOutput))),
 			fmt.Sprintf("CURRENT_GID=%s", strings.TrimSpace(string(gidOutput))),
 			fmt.Sprintf("AVALANCHEGO_TRACK_SUBNETS=%s", subnetID),
+			fmt.Sprintf("AVALANCHEGO_NETWORK_ID=%s", aggregatorNetwork().NetworkIDFlagValue()),
+			fmt.Sprintf("AVALANCHEGO_HTTP_PORT=%d", httpPort),
+			fmt.Sprintf("AVALANCHEGO_STAKING_PORT=%d", httpPort+1),
+			fmt.Sprintf("NODE_CONTAINER_NAME=%s", helpers.ContainerName(0)),
+			fmt.Sprintf("DATA_DIR=%s", dataDir),
+			fmt.Sprintf("ETNA_WORKSPACE=%s", helpers.CurrentWorkspace),
 		}
 
 		// Change working directory for docker compose commands
-		downCmd := exec.Command("docker", "compose", "down")
+		downCmd := exec.Command("docker", "compose", "-p", helpers.ComposeProjectName(), "down")
 		downCmd.Dir = "./cmd/node"
 		downCmd.Env = append(downCmd.Env, env...)
 		output, err := downCmd.CombinedOutput()
@@ -94,7 +112,7 @@ var launchNodeCmd = &cobra.Command{
 			log.Printf("Docker compose down output:\n%s", output)
 		}
 
-		upCmd := exec.Command("docker", "compose", "up", "-d", "--build")
+		upCmd := exec.Command("docker", "compose", "-p", helpers.ComposeProjectName(), "up", "-d", "--build")
 		upCmd.Dir = "./cmd/node"
 		upCmd.Env = append(upCmd.Env, env...)
 		output, err = upCmd.CombinedOutput()
@@ -103,22 +121,31 @@ var launchNodeCmd = &cobra.Command{
 		}
 		log.Printf("Docker compose up output:\n%s", output)
 
-		_, evmChainId, err := GetLocalEthClient("9650")
+		_, evmChainId, err := GetNode0EthClient()
 		if err != nil {
 			return fmt.Errorf("failed to wait for chain to be available: %w", err)
 		}
 
 		fmt.Printf("✅ Subnet is healthy and responding\n")
 		fmt.Printf("Chain ID (decimal): %d\n", evmChainId.Int64())
-		fmt.Printf("To see logs, run: docker logs -f node0\n")
+		fmt.Printf("To see logs, run: docker logs -f %s\n", helpers.ContainerName(0))
 
 		return nil
 	},
 }
 
+// GetNode0EthClient connects to the L1 on node0 of the current workspace
+func GetNode0EthClient() (ethclient.Client, *big.Int, error) {
+	port, err := helpers.NodeHTTPPort(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get node0 port: %w", err)
+	}
+	return GetLocalEthClient(strconv.Itoa(port))
+}
+
 func GetLocalEthClient(port string) (ethclient.Client, *big.Int, error) {
 	const maxAttempts = 100
-	L1ChainId, err := helpers.LoadId(helpers.ChainIdPath)
+	L1ChainId, err := helpers.LoadChainID()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load chain ID: %w", err)
 	}
@@ -13,7 +13,6 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/spf13/cobra"
 
-	"github.com/ava-labs/coreth/plugin/evm"
 	examplerewardcalculator "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/ExampleRewardCalculator"
 	nativetokenstakingmanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/NativeTokenStakingManager"
 	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
@@ -29,29 +28,38 @@ func init() {
 	rootCmd.AddCommand(deployValidatorManagerCmd)
 	deployValidatorManagerCmd.Flags().StringVar(&validatorType, "validator-type", "", fmt.Sprintf("Type of validator manager to deploy (%s or %s)", config.PoAMode, config.PoSNativeMode))
 	deployValidatorManagerCmd.MarkFlagRequired("validator-type")
+	deployValidatorManagerCmd.Flags().StringVar(&managerArtifactPath, "artifact", "", "Deploy the manager from a Foundry or Hardhat artifact JSON instead of the icm-contracts bindings")
+	deployValidatorManagerCmd.Flags().StringArrayVar(&managerLibraries, "library", nil, "Library the --artifact links: <name>=<0xaddress> to use a deployed one or <name>=<artifact.json> to deploy it first, <name> being <file>:<name> or just the name if it is unique")
+	deployValidatorManagerCmd.Flags().StringVar(&managerConstructorArgs, "constructor-args", "", "Constructor arguments of the --artifact as a JSON array, like '[0]'")
 }
 
 var deployValidatorManagerCmd = &cobra.Command{
 	Use:   "deploy-validator-manager",
 	Short: "Deploy the validator manager contract",
+	Long: `Deploy the validator manager contract.
+
+By default the icm-contracts PoA or native token staking manager is deployed.
+With --artifact a manager compiled by Foundry (out/<file>.sol/<name>.json) or
+Hardhat is deployed instead, like a fork with custom registration rules. Its
+ABI must have the initialize of --validator-type and the methods the add and
+remove validator steps call. Libraries it links are given with --library,
+either deployed already or as artifacts deployed before the manager, and
+named <source file>:<name> like src/ValidatorMessages.sol:ValidatorMessages,
+or by their name alone if no other linked library shares it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🚀 Deploying validator manager")
-		privKey, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+		ethClient, evmChainId, err := GetNode0EthClient()
 		if err != nil {
-			return fmt.Errorf("failed to load private key: %w", err)
-		}
-		privKeyECDSA, err := helpers.LoadSecp256k1PrivateKeyECDSA(helpers.ValidatorManagerOwnerKeyPath)
-		if err != nil {
-			return fmt.Errorf("failed to load private key: %w", err)
+			return fmt.Errorf("failed to connect to client: %w", err)
 		}
 
-		ethClient, evmChainId, err := GetLocalEthClient("9650")
+		// The genesis points the proxy at the implementation, either embedded
+		// at a fixed address or where the owner's second tx deploys it
+		implementationSlot, err := ethClient.StorageAt(context.Background(), common.HexToAddress(config.ProxyContractAddress), eip1967ImplementationSlot, nil)
 		if err != nil {
-			return fmt.Errorf("failed to connect to client: %w", err)
+			return fmt.Errorf("failed to get proxy implementation: %w", err)
 		}
-
-		myEthAddr := evm.PublicKeyToEthAddress(privKey.PublicKey())
-		expectedContractAddress := MustDeriveContractAddress(myEthAddr, 1)
+		expectedContractAddress := common.BytesToAddress(implementationSlot)
 
 		deployedBytecode, err := ethClient.CodeAt(context.Background(), expectedContractAddress, nil)
 		if err != nil {
@@ -59,14 +67,27 @@ var deployValidatorManagerCmd = &cobra.Command{
 		}
 
 		if len(deployedBytecode) > 0 {
+			manifest, err := helpers.LoadManifest()
+			if err != nil {
+				return fmt.Errorf("failed to load manifest: %w", err)
+			}
+			if manifest.ManagerInGenesis && validatorType != manifest.ValidatorType {
+				return fmt.Errorf("the genesis embeds the %s validator manager, not %s", manifest.ValidatorType, validatorType)
+			}
 			log.Printf("Validator manager already deployed at: %s\n", expectedContractAddress)
 			return nil
 		}
 
-		opts, err := bind.NewKeyedTransactorWithChainID(privKeyECDSA, evmChainId)
+		signer, err := ownerSigner()
 		if err != nil {
-			return fmt.Errorf("failed to create transactor: %w", err)
+			return fmt.Errorf("failed to load private key: %w", err)
+		}
+		myEthAddr := signer.EthAddress()
+		if expectedContractAddress != MustDeriveContractAddress(myEthAddr, 1) {
+			return fmt.Errorf("the proxy points at %s, which has no code and isn't where the owner deploys to; start over in a new workspace with `workspace create <name>`", expectedContractAddress)
 		}
+
+		opts := helpers.NewSignerTransactor(signer, evmChainId)
 		opts.GasLimit = 8000000
 		opts.GasPrice = nil
 
@@ -74,7 +95,12 @@ var deployValidatorManagerCmd = &cobra.Command{
 		var tx *types.Transaction
 		var exampleRewardCalculatorAddress common.Address
 
-		if validatorType == config.PoAMode {
+		if managerArtifactPath != "" {
+			newContractAddress, tx, err = deployManagerArtifact(opts, ethClient, myEthAddr, expectedContractAddress)
+			if err != nil {
+				return err
+			}
+		} else if validatorType == config.PoAMode {
 			newContractAddress, tx, _, err = poavalidatormanager.DeployPoAValidatorManager(opts, ethClient, 0)
 			if err != nil {
 				return fmt.Errorf("failed to create contract instance: %w", err)
@@ -102,7 +128,7 @@ var deployValidatorManagerCmd = &cobra.Command{
 				MustDeriveContractAddress(myEthAddr, 3),
 				MustDeriveContractAddress(myEthAddr, 4),
 			)
-			return fmt.Errorf("expected contract address %s, got %s", expectedContractAddress, newContractAddress)
+			return fmt.Errorf("expected contract address %s, got %s; the owner must not send other transactions first, or use generate-genesis --manager-in-genesis", expectedContractAddress, newContractAddress)
 		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
@@ -113,7 +139,11 @@ var deployValidatorManagerCmd = &cobra.Command{
 			return fmt.Errorf("failed to wait for transaction confirmation: %w", err)
 		}
 
-		err = helpers.SaveAddress(helpers.ExampleRewardCalculatorAddressPath, exampleRewardCalculatorAddress)
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			manifest.ValidatorType = validatorType
+			manifest.ExampleRewardCalculatorAddress = exampleRewardCalculatorAddress
+			return nil
+		})
 		if err != nil {
 			return fmt.Errorf("failed to save example reward calculator address: %w", err)
 		}
@@ -133,3 +163,14 @@ func MustDeriveContractAddress(from common.Address, nonce uint64) common.Address
 	hash := crypto.Keccak256(encoded)
 	return common.BytesToAddress(hash[12:])
 }
+
+// contractNonce is the nonce at which from creates the contract at address,
+// if it is at most limit
+func contractNonce(from common.Address, address common.Address, limit uint64) (uint64, bool) {
+	for nonce := uint64(0); nonce <= limit; nonce++ {
+		if MustDeriveContractAddress(from, nonce) == address {
+			return nonce, true
+		}
+	}
+	return 0, false
+}
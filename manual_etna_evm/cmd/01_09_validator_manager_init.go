@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -20,7 +19,6 @@ import (
 
 	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
 )
@@ -38,32 +36,28 @@ var validatorManagerInitCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🔌 Initializing validator manager (EVM transaction)")
 
-		ecdsaKey, err := helpers.LoadSecp256k1PrivateKeyECDSA(helpers.ValidatorManagerOwnerKeyPath)
+		signer, err := ownerSigner()
 		if err != nil {
 			return fmt.Errorf("failed to load private key: %w", err)
 		}
 
 		managerAddress := common.HexToAddress(config.ProxyContractAddress)
-		ethClient, evmChainId, err := GetLocalEthClient("9650")
+		ethClient, evmChainId, err := GetNode0EthClient()
 		if err != nil {
 			return fmt.Errorf("failed to connect to client: %w", err)
 		}
 
 		// Check for Initialized event in logs
 
-		subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+		subnetID, err := helpers.LoadSubnetID()
 		if err != nil {
 			return fmt.Errorf("failed to load subnet ID: %w", err)
 		}
 
-		key, err := helpers.LoadSecp256k1PrivateKeyECDSA(helpers.ValidatorManagerOwnerKeyPath)
+		opts := helpers.NewSignerTransactor(signer, evmChainId)
+		managerOwner, err := roleEthAddress(helpers.RoleManagerOwner)
 		if err != nil {
-			return fmt.Errorf("failed to load private key: %w", err)
-		}
-
-		opts, err := bind.NewKeyedTransactorWithChainID(key, evmChainId)
-		if err != nil {
-			return fmt.Errorf("failed to create transactor: %w", err)
+			return err
 		}
 		opts.GasLimit = 8000000
 		opts.GasPrice = nil
@@ -72,12 +66,12 @@ var validatorManagerInitCmd = &cobra.Command{
 		var tx *types.Transaction
 
 		if validatorType == config.PoAMode {
-			receipt, tx, err = initializeValidatorManagerPoA(validatorType, managerAddress, ethClient, subnetID, opts, ecdsaKey.PublicKey)
+			receipt, tx, err = initializeValidatorManagerPoA(validatorType, managerAddress, ethClient, subnetID, opts, managerOwner)
 			if err != nil {
 				return fmt.Errorf("failed to initialize validator manager: %w", err)
 			}
 		} else if validatorType == config.PoSNativeMode {
-			receipt, tx, err = initializeValidatorManagerPoSNativeTokenStaking(validatorType, managerAddress, ethClient, subnetID, opts, ecdsaKey.PublicKey)
+			receipt, tx, err = initializeValidatorManagerPoSNativeTokenStaking(validatorType, managerAddress, ethClient, subnetID, opts, managerOwner)
 			if err != nil {
 				return fmt.Errorf("failed to initialize validator manager: %w", err)
 			}
@@ -93,7 +87,7 @@ var validatorManagerInitCmd = &cobra.Command{
 	},
 }
 
-func initializeValidatorManagerPoA(validatorManagerType string, managerAddress common.Address, ethClient ethclient.Client, subnetID ids.ID, opts *bind.TransactOpts, ecdsaPubKey ecdsa.PublicKey) (*types.Receipt, *types.Transaction, error) {
+func initializeValidatorManagerPoA(validatorManagerType string, managerAddress common.Address, ethClient ethclient.Client, subnetID ids.ID, opts *bind.TransactOpts, owner common.Address) (*types.Receipt, *types.Transaction, error) {
 	logs, err := ethClient.FilterLogs(context.Background(), interfaces.FilterQuery{
 		Addresses: []common.Address{managerAddress},
 	})
@@ -122,7 +116,7 @@ func initializeValidatorManagerPoA(validatorManagerType string, managerAddress c
 		L1ID:                   subnetID,
 		ChurnPeriodSeconds:     0,
 		MaximumChurnPercentage: 20,
-	}, crypto.PubkeyToAddress(ecdsaPubKey))
+	}, owner)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize validator manager: %w", err)
 	}
@@ -135,7 +129,7 @@ func initializeValidatorManagerPoA(validatorManagerType string, managerAddress c
 	return receipt, tx, nil
 }
 
-func initializeValidatorManagerPoSNativeTokenStaking(validatorManagerType string, managerAddress common.Address, ethClient ethclient.Client, subnetID ids.ID, opts *bind.TransactOpts, ecdsaPubKey ecdsa.PublicKey) (*types.Receipt, *types.Transaction, error) {
+func initializeValidatorManagerPoSNativeTokenStaking(validatorManagerType string, managerAddress common.Address, ethClient ethclient.Client, subnetID ids.ID, opts *bind.TransactOpts, owner common.Address) (*types.Receipt, *types.Transaction, error) {
 	logs, err := ethClient.FilterLogs(context.Background(), interfaces.FilterQuery{
 		Addresses: []common.Address{managerAddress},
 	})
@@ -160,12 +154,13 @@ func initializeValidatorManagerPoSNativeTokenStaking(validatorManagerType string
 	}
 	log.Println("Validator manager was not initialized, initializing...")
 
-	rewardCalculatorAddress, err := helpers.LoadAddress(helpers.ExampleRewardCalculatorAddressPath)
+	manifest, err := helpers.LoadManifest()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load reward calculator address: %w", err)
+		return nil, nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
+	rewardCalculatorAddress := manifest.ExampleRewardCalculatorAddress
 
-	chainId, err := helpers.LoadId(helpers.ChainIdPath)
+	chainId, err := helpers.LoadChainID()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load chain ID: %w", err)
 	}
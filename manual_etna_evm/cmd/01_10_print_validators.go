@@ -79,7 +79,7 @@ type ValidatorsResponse struct {
 	Validators map[string]ValidatorInfo
 }
 
-func callPChainValidatorsAt(fujiPChainURL string, subnetID string) (*ValidatorsResponse, error) {
+func callPChainValidatorsAt(pChainURL string, subnetID string) (*ValidatorsResponse, error) {
 	client := &http.Client{}
 	validatorsPayload := map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -91,7 +91,7 @@ func callPChainValidatorsAt(fujiPChainURL string, subnetID string) (*ValidatorsR
 		"id": 1,
 	}
 
-	resp, err := makeJSONRPCRequest(client, fujiPChainURL, validatorsPayload)
+	resp, err := makeJSONRPCRequest(client, pChainURL, validatorsPayload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -122,17 +122,17 @@ func callPChainValidatorsAt(fujiPChainURL string, subnetID string) (*ValidatorsR
 }
 
 func printPChainState() error {
-	subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+	subnetID, err := helpers.LoadSubnetID()
 	if err != nil {
 		return fmt.Errorf("failed to load subnet ID: %w", err)
 	}
 
 	// Make HTTP requests
 	client := &http.Client{}
-	fujiPChainURL := "https://api.avax-test.network/ext/P"
+	pChainURL := currentNetwork.PChainEndpoint()
 
 	// Get validators
-	validatorsResp, err := callPChainValidatorsAt(fujiPChainURL, subnetID.String())
+	validatorsResp, err := callPChainValidatorsAt(pChainURL, subnetID.String())
 	if err != nil {
 		return fmt.Errorf("failed to get validators: %w", err)
 	}
@@ -147,7 +147,7 @@ func printPChainState() error {
 		"id": 1,
 	}
 
-	subnetResp, err := makeJSONRPCRequest(client, fujiPChainURL, subnetPayload)
+	subnetResp, err := makeJSONRPCRequest(client, pChainURL, subnetPayload)
 	if err != nil {
 		return fmt.Errorf("failed to get subnet info: %w", err)
 	}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
 	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
 	"github.com/ava-labs/subnet-evm/interfaces"
 	"github.com/ethereum/go-ethereum"
@@ -26,7 +28,11 @@ var printContractLogsCmd = &cobra.Command{
 		if len(args) >= 1 {
 			port = args[0]
 		} else {
-			port = "9650"
+			node0Port, err := helpers.NodeHTTPPort(0)
+			if err != nil {
+				return err
+			}
+			port = strconv.Itoa(node0Port)
 		}
 
 		PrintHeader(fmt.Sprintf("🧱 Printing contract logs from localhost:%s", port))
@@ -1,27 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/big"
-	"strings"
 	"time"
 
-	"github.com/ava-labs/avalanche-cli/cmd/blockchaincmd"
-	"github.com/ava-labs/avalanche-cli/pkg/constants"
-	"github.com/ava-labs/avalanche-cli/pkg/contract"
-	"github.com/ava-labs/avalanche-cli/pkg/models"
 	"github.com/ava-labs/avalanche-cli/sdk/interchain"
 	"github.com/ava-labs/avalanche-cli/sdk/validatormanager"
+	"github.com/ava-labs/avalanchego/ids"
 	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
-	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
 	"github.com/ethereum/go-ethereum/common"
-	goethereumcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +31,24 @@ var initializeValidatorSetCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		PrintHeader("🧱 Initializing validator set")
 
+		manifest, err := helpers.LoadManifest()
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		if manifest.InitializeValidatorSetTx != "" {
+			log.Println("✅ Validator set is already initialized")
+			return nil
+		}
+
+		// A conversion ID that doesn't match won't get better with retries
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		conversionData, conversionID, err := verifiedConversionData(ctx, platformvm.NewClient(currentNetwork.PChainURI), manifest)
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Conversion ID %s matches the P-chain\n", conversionID)
+
 		const maxAttempts = 10
 		const retryDelay = 10 * time.Second
 
@@ -45,7 +59,7 @@ var initializeValidatorSetCmd = &cobra.Command{
 				time.Sleep(retryDelay)
 			}
 
-			err := initializeValidatorSet()
+			err := initializeValidatorSet(conversionData, conversionID)
 			if err == nil {
 				return nil
 			}
@@ -57,59 +71,15 @@ var initializeValidatorSetCmd = &cobra.Command{
 	},
 }
 
-func initializeValidatorSet() error {
-	alreadyInitialized, err := helpers.FileExists(helpers.InitializeValidatorSetTxPath)
-	if err != nil {
-		return fmt.Errorf("failed to check if validator set is already initialized: %w", err)
-	}
-	if alreadyInitialized {
-		log.Println("✅ Validator set is already initialized")
-		return nil
-	}
-
-	managerAddress := goethereumcommon.HexToAddress(config.ProxyContractAddress)
+// initializeValidatorSet sends the validators of the conversion to the
+// manager. The warp message and the call are both built from conversionData,
+// which hashes to conversionID.
+func initializeValidatorSet(conversionData *helpers.ConversionData, conversionID ids.ID) error {
+	subnetID := conversionData.SubnetID
+	chainID := conversionData.ManagerChainID
+	managerAddress := common.BytesToAddress(conversionData.ManagerAddress)
 
-	subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
-	if err != nil {
-		return fmt.Errorf("failed to load subnet ID: %w", err)
-	}
-
-	subnetConversionIDFromFile, err := helpers.LoadId(helpers.ConversionIdPath)
-	if err != nil {
-		return fmt.Errorf("failed to load subnet conversion ID: %w", err)
-	}
-
-	_ = subnetConversionIDFromFile
-
-	nodeID, proofOfPossession, err := helpers.GetNodeInfoRetry(fmt.Sprintf("http://%s:%s", "127.0.0.1", "9650"))
-	if err != nil {
-		return fmt.Errorf("failed to get node info: %w", err)
-	}
-
-	chainID, err := helpers.LoadId(helpers.ChainIdPath)
-	if err != nil {
-		return fmt.Errorf("failed to load chain ID: %w", err)
-	}
-
-	validators := []message.SubnetToL1ConverstionValidatorData{}
-	validators = append(validators, message.SubnetToL1ConverstionValidatorData{
-		NodeID:       nodeID[:],
-		BLSPublicKey: proofOfPossession.PublicKey,
-		Weight:       constants.BootstrapValidatorWeight,
-	})
-
-	subnetConversionData := message.SubnetToL1ConversionData{
-		SubnetID:       subnetID,
-		ManagerChainID: chainID,
-		ManagerAddress: managerAddress.Bytes(),
-		Validators:     validators,
-	}
-	subnetConversionID, err := message.SubnetToL1ConversionID(subnetConversionData)
-	if err != nil {
-		return fmt.Errorf("failed to create subnet conversion ID: %w", err)
-	}
-
-	addressedCallPayload, err := message.NewSubnetToL1Conversion(subnetConversionID)
+	addressedCallPayload, err := message.NewSubnetToL1Conversion(conversionID)
 	if err != nil {
 		return fmt.Errorf("failed to create addressed call payload: %w", err)
 	}
@@ -122,7 +92,7 @@ func initializeValidatorSet() error {
 		return fmt.Errorf("failed to create addressed call payload: %w", err)
 	}
 
-	network := models.NewFujiNetwork()
+	network := aggregatorNetwork()
 
 	subnetConversionUnsignedMessage, err := warp.NewUnsignedMessage(
 		network.ID,
@@ -133,9 +103,9 @@ func initializeValidatorSet() error {
 		return fmt.Errorf("failed to create unsigned message: %w", err)
 	}
 
-	peers, err := blockchaincmd.ConvertURIToPeers([]string{"http://127.0.0.1:9650"})
+	peers, err := aggregatorPeers()
 	if err != nil {
-		return fmt.Errorf("failed to get extra peers: %w", err)
+		return err
 	}
 
 	signatureAggregator, err := interchain.NewSignatureAggregator(
@@ -155,8 +125,7 @@ func initializeValidatorSet() error {
 		return fmt.Errorf("failed to sign subnet conversion unsigned message: %w", err)
 	}
 
-	//as plain text
-	privateKey, err := helpers.LoadText(helpers.ValidatorManagerOwnerKeyPath)
+	signer, err := ownerSigner()
 	if err != nil {
 		return fmt.Errorf("failed to load private key: %w", err)
 	}
@@ -173,22 +142,29 @@ func initializeValidatorSet() error {
 		InitialValidators            []InitialValidatorPayload
 	}
 
+	initialValidators := make([]InitialValidatorPayload, len(conversionData.Validators))
+	for i, validator := range conversionData.Validators {
+		initialValidators[i] = InitialValidatorPayload{
+			NodeID:       validator.NodeID,
+			BlsPublicKey: validator.BLSPublicKey,
+			Weight:       validator.Weight,
+		}
+	}
 	subnetConversionDataPayload := SubnetConversionDataPayload{
 		SubnetID:                     subnetID,
 		ValidatorManagerBlockchainID: chainID,
 		ValidatorManagerAddress:      managerAddress,
-		InitialValidators: []InitialValidatorPayload{
-			{
-				NodeID:       nodeID[:],
-				BlsPublicKey: proofOfPossession.PublicKey[:],
-				Weight:       constants.BootstrapValidatorWeight,
-			},
-		},
+		InitialValidators:            initialValidators,
 	}
 
-	tx, _, err := contract.TxToMethodWithWarpMessage(
-		fmt.Sprintf("http://127.0.0.1:9650/ext/bc/%s/rpc", chainID),
-		strings.TrimSpace(privateKey),
+	rpcURL, err := helpers.NodeRPCURL(0, chainID)
+	if err != nil {
+		return err
+	}
+
+	tx, _, err := txToMethodWithWarpMessage(
+		rpcURL,
+		signer,
 		managerAddress,
 		subnetConversionSignedMessage,
 		big.NewInt(0),
@@ -204,7 +180,8 @@ func initializeValidatorSet() error {
 
 	fmt.Printf("✅ Successfully initialized validator set. Transaction hash: %s\n", tx.Hash().String())
 
-	helpers.SaveText(helpers.InitializeValidatorSetTxPath, tx.Hash().String())
-
-	return nil
+	return helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+		manifest.InitializeValidatorSetTx = tx.Hash().String()
+		return nil
+	})
 }
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/hex"
 	"fmt"
 	"log"
 	"math/big"
@@ -13,9 +12,7 @@ import (
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
 	"github.com/spf13/cobra"
 
-	"github.com/ava-labs/avalanche-cli/cmd/blockchaincmd"
 	"github.com/ava-labs/avalanche-cli/pkg/constants"
-	"github.com/ava-labs/avalanche-cli/pkg/contract"
 	"github.com/ava-labs/avalanche-cli/pkg/models"
 	"github.com/ava-labs/avalanche-cli/pkg/utils"
 	"github.com/ava-labs/avalanche-cli/sdk/interchain"
@@ -30,7 +27,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+var addValidatorBalance string
+
 func init() {
+	AddPoaValidatorCmd.Flags().StringVar(&addValidatorBalance, "balance", "1", "AVAX the new validator gets to pay the continuous fee, like 1.5")
+	addUnsignedOutFlag(AddPoaValidatorCmd)
 	rootCmd.AddCommand(AddPoaValidatorCmd)
 }
 
@@ -38,6 +39,14 @@ var AddPoaValidatorCmd = &cobra.Command{
 	Use:   "add-poa-validator",
 	Short: "Add a validator to the validator set",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		balance, err := validatorBalance(addValidatorBalance)
+		if err != nil {
+			return err
+		}
+		if err := checkAddValidatorFunds(balance); err != nil {
+			return err
+		}
+
 		credsFolder, nodeIndex, err := generateAddValidatorFolder()
 		if err != nil {
 			return fmt.Errorf("failed to generate add validator folder: %w", err)
@@ -59,10 +68,28 @@ var AddPoaValidatorCmd = &cobra.Command{
 		log.Printf("Validation ID: %s\n", validationID)
 		log.Printf("Expiry: %d\n", expiry)
 
+		nodeID, _, err := NodeInfoFromCreds(credsFolder)
+		if err != nil {
+			return fmt.Errorf("failed to get node info from creds: %w", err)
+		}
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			manifest.SetValidator(helpers.ManifestValidator{
+				NodeID:       nodeID,
+				ValidationID: validationID,
+				Weight:       constants.NonBootstrapValidatorWeight,
+				Balance:      balance,
+				CredsFolder:  credsFolder,
+			})
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record validator in manifest: %w", err)
+		}
+
 		pChainRegistrationCompleted := false
 		for i := 0; i < 5; i++ {
 			log.Printf("Attempting to register L1 validator on P-chain (attempt %d/5)...", i+1)
-			err = RegisterL1ValidatorOnPChain(warpMessage, credsFolder)
+			err = RegisterL1ValidatorOnPChain(warpMessage, credsFolder, balance)
 			if err != nil {
 				log.Printf("Attempt %d failed: %s", i+1, err)
 				if i < 4 {
@@ -104,7 +131,7 @@ var AddPoaValidatorCmd = &cobra.Command{
 
 func generateAddValidatorFolder() (string, int, error) {
 	for i := 1; i < 100; i++ { //has to start with 1. node0 is already registered
-		folderName := fmt.Sprintf("data/add_validator_%d/", i)
+		folderName := fmt.Sprintf("%sadd_validator_%d/", helpers.DataDir, i)
 		exists, err := helpers.FileExists(folderName)
 		if err != nil {
 			return "", 0, fmt.Errorf("failed to check if folder exists: %w", err)
@@ -127,26 +154,38 @@ func InitValidatorRegistration(credsFolder string) (*warp.Message, ids.ID, uint6
 		return nil, ids.Empty, 0, fmt.Errorf("failed to get node info from creds: %w", err)
 	}
 
-	chainID, err := helpers.LoadId(helpers.ChainIdPath)
+	chainID, err := helpers.LoadChainID()
 	if err != nil {
 		return nil, ids.Empty, 0, fmt.Errorf("failed to load chain ID: %w", err)
 	}
-	evmChainURL := fmt.Sprintf("http://127.0.0.1:9650/ext/bc/%s/rpc", chainID)
+	evmChainURL, err := helpers.NodeRPCURL(0, chainID)
+	if err != nil {
+		return nil, ids.Empty, 0, err
+	}
 
 	expiry := uint64(time.Now().Add(constants.DefaultValidationIDExpiryDuration).Unix())
 
-	managerKey, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+	managerOwner, err := roleSigner(helpers.RoleManagerOwner)
 	if err != nil {
 		return nil, ids.Empty, 0, fmt.Errorf("failed to load manager key: %w", err)
 	}
-
-	pChainAddr := managerKey.Address()
+	balanceOwner, err := roleAddress(helpers.RoleValidatorBalanceOwner)
+	if err != nil {
+		return nil, ids.Empty, 0, err
+	}
+	disableOwner, err := roleAddress(helpers.RoleValidatorDisableOwner)
+	if err != nil {
+		return nil, ids.Empty, 0, err
+	}
 
 	remainingBalanceOwners := warpMessage.PChainOwner{
 		Threshold: 1,
-		Addresses: []ids.ShortID{pChainAddr},
+		Addresses: []ids.ShortID{balanceOwner},
+	}
+	disableOwners := warpMessage.PChainOwner{
+		Threshold: 1,
+		Addresses: []ids.ShortID{disableOwner},
 	}
-	disableOwners := remainingBalanceOwners
 
 	managerAddress := common.HexToAddress(config.ProxyContractAddress)
 
@@ -155,7 +194,7 @@ func InitValidatorRegistration(credsFolder string) (*warp.Message, ids.ID, uint6
 	_, receipt, err := PoAValidatorManagerInitializeValidatorRegistration(
 		evmChainURL,
 		managerAddress,
-		hex.EncodeToString(managerKey.Bytes()),
+		managerOwner,
 		nodeID,
 		proofOfPossession.PublicKey[:],
 		expiry,
@@ -174,22 +213,22 @@ func InitValidatorRegistration(credsFolder string) (*warp.Message, ids.ID, uint6
 		log.Printf("✅ Validator registration initialized: %s\n", receipt.TxHash)
 	}
 
-	log.Println("Validator registration initialized in the contract, collecting signatures...\n")
+	log.Println("Validator registration initialized in the contract, collecting signatures...")
 
-	network := models.NewFujiNetwork()
+	network := aggregatorNetwork()
 	aggregatorLogLevel := logging.Level(logging.Info)
 	aggregatorQuorumPercentage := uint64(0)
 	aggregatorAllowPrivateIPs := true
 
-	aggregatorExtraPeerEndpoints, err := blockchaincmd.ConvertURIToPeers([]string{"http://127.0.0.1:9650"})
+	aggregatorExtraPeerEndpoints, err := aggregatorPeers()
 	if err != nil {
-		return nil, ids.Empty, 0, fmt.Errorf("failed to get extra peers: %w", err)
+		return nil, ids.Empty, 0, err
 	}
 
 	blsPublicKey := [48]byte(proofOfPossession.PublicKey[:])
 	weight := constants.NonBootstrapValidatorWeight
 
-	subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+	subnetID, err := helpers.LoadSubnetID()
 	if err != nil {
 		return nil, ids.Empty, 0, fmt.Errorf("failed to load subnet ID: %w", err)
 	}
@@ -280,7 +319,7 @@ func ValidatorManagerGetSubnetValidatorRegistrationMessage(
 func PoAValidatorManagerInitializeValidatorRegistration(
 	rpcURL string,
 	managerAddress common.Address,
-	managerOwnerPrivateKey string,
+	managerOwner helpers.Signer,
 	nodeID ids.NodeID,
 	blsPublicKey []byte,
 	expiry uint64,
@@ -319,9 +358,9 @@ func PoAValidatorManagerInitializeValidatorRegistration(
 		DisableOwner:          disableOwnersAux,
 	}
 
-	return contract.TxToMethod(
+	return txToMethod(
 		rpcURL,
-		managerOwnerPrivateKey,
+		managerOwner,
 		managerAddress,
 		big.NewInt(0),
 		"initialize validator registration",
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	_ "embed"
-	"encoding/hex"
 	"fmt"
 	"log"
 	"math/big"
@@ -10,8 +9,6 @@ import (
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
 	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
 
-	"github.com/ava-labs/avalanche-cli/cmd/blockchaincmd"
-	"github.com/ava-labs/avalanche-cli/pkg/contract"
 	"github.com/ava-labs/avalanche-cli/pkg/evm"
 	"github.com/ava-labs/avalanche-cli/pkg/models"
 	"github.com/ava-labs/avalanche-cli/pkg/utils"
@@ -34,18 +31,21 @@ import (
 
 func AddValidatorCompleteRegistration(validationID ids.ID) error {
 	registered := true
-	aggregatorExtraPeerEndpoints, err := blockchaincmd.ConvertURIToPeers([]string{"http://127.0.0.1:9650"})
+	aggregatorExtraPeerEndpoints, err := aggregatorPeers()
 	if err != nil {
-		log.Fatalf("failed to get extra peers: %s", err)
+		return err
 	}
 	aggregatorQuorumPercentage := uint64(0)
-	subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+	subnetID, err := helpers.LoadSubnetID()
 	if err != nil {
 		return fmt.Errorf("failed to load subnet id: %w", err)
 	}
-	rpcURL := "http://127.0.0.1:9650"
+	rpcURL, err := helpers.NodeURI(0)
+	if err != nil {
+		return err
+	}
 
-	network := models.NewFujiNetwork()
+	network := aggregatorNetwork()
 	aggregatorLogLevel := logging.Level(logging.Info)
 	aggregatorAllowPrivateIPs := true
 
@@ -68,22 +68,25 @@ func AddValidatorCompleteRegistration(validationID ids.ID) error {
 
 	managerAddress := goethereumcommon.HexToAddress(config.ProxyContractAddress)
 
-	privateKey, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+	signer, err := ownerSigner()
 	if err != nil {
 		return fmt.Errorf("failed to load manager key: %w", err)
 	}
 
-	chainID, err := helpers.LoadId(helpers.ChainIdPath)
+	chainID, err := helpers.LoadChainID()
 	if err != nil {
 		return fmt.Errorf("failed to load chain id: %w", err)
 	}
 
-	nodeURL := fmt.Sprintf("http://%s:%s/ext/bc/%s/rpc", "127.0.0.1", "9650", chainID)
+	nodeURL, err := helpers.NodeRPCURL(0, chainID)
+	if err != nil {
+		return err
+	}
 
 	tx, _, err := ValidatorManagerCompleteValidatorRegistration(
 		nodeURL,
 		managerAddress,
-		hex.EncodeToString(privateKey.Bytes()),
+		signer,
 		signedMessage,
 	)
 	if err != nil {
@@ -97,12 +100,12 @@ func AddValidatorCompleteRegistration(validationID ids.ID) error {
 func ValidatorManagerCompleteValidatorRegistration(
 	rpcURL string,
 	managerAddress goethereumcommon.Address,
-	privateKey string, // not need to be owner atm
+	signer helpers.Signer, // not need to be owner atm
 	subnetValidatorRegistrationSignedMessage *warp.Message,
 ) (*types.Transaction, *types.Receipt, error) {
-	return contract.TxToMethodWithWarpMessage(
+	return txToMethodWithWarpMessage(
 		rpcURL,
-		privateKey,
+		signer,
 		managerAddress,
 		subnetValidatorRegistrationSignedMessage,
 		big.NewInt(0),
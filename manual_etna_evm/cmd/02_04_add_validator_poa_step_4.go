@@ -12,8 +12,8 @@ func GetValidatorCMD(credsFolder string, nodeIndex int) (string, error) {
 		return "", fmt.Errorf("node index cannot be 0")
 	}
 
-	containerName := fmt.Sprintf("node%d", nodeIndex)
-	subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+	containerName := helpers.ContainerName(nodeIndex)
+	subnetID, err := helpers.LoadSubnetID()
 	if err != nil {
 		return "", fmt.Errorf("failed to load subnet id: %w", err)
 	}
@@ -23,7 +23,10 @@ func GetValidatorCMD(credsFolder string, nodeIndex int) (string, error) {
 		return "", fmt.Errorf("failed to get creds base64: %w", err)
 	}
 
-	httpPort := 9650 + (nodeIndex)*2
+	httpPort, err := helpers.NodeHTTPPort(nodeIndex)
+	if err != nil {
+		return "", fmt.Errorf("failed to get node port: %w", err)
+	}
 	stakingPort := httpPort + 1
 
 	script := fmt.Sprintf(`
@@ -31,7 +34,8 @@ docker rm -f %s || true; \
 docker run -d \
   --name %s \
   --network host \
-  -e AVALANCHEGO_NETWORK_ID=fuji \
+  --label %s=%s \
+  -e AVALANCHEGO_NETWORK_ID=%s \
   -e AVALANCHEGO_HTTP_PORT=%d \
   -e AVALANCHEGO_STAKING_PORT=%d \
   -e AVALANCHEGO_TRACK_SUBNETS=%s \
@@ -44,7 +48,7 @@ docker run -d \
   -e AVALANCHEGO_PARTIAL_SYNC_PRIMARY_NETWORK=true \
   containerman17/avalanchego-subnetevm:v1.12.0_v0.7.0 ;
 
-	`, containerName, containerName, httpPort, stakingPort, subnetID.String(), stakerCertBase64, stakerKeyBase64, signerKeyBase64)
+	`, containerName, containerName, helpers.ContainerLabel, helpers.CurrentWorkspace, aggregatorNetwork().NetworkIDFlagValue(), httpPort, stakingPort, subnetID.String(), stakerCertBase64, stakerKeyBase64, signerKeyBase64)
 
 	return script, nil
 }
@@ -1,14 +1,12 @@
 package cmd
 
 import (
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"time"
 
-	"github.com/ava-labs/avalanche-cli/cmd/blockchaincmd"
 	"github.com/ava-labs/avalanche-cli/pkg/contract"
 	"github.com/ava-labs/avalanche-cli/pkg/evm"
 	"github.com/ava-labs/avalanche-cli/pkg/models"
@@ -28,6 +26,7 @@ import (
 )
 
 func init() {
+	addUnsignedOutFlag(removeValidatorStep1Cmd)
 	rootCmd.AddCommand(removeValidatorStep1Cmd)
 }
 
@@ -35,12 +34,12 @@ var removeValidatorStep1Cmd = &cobra.Command{
 	Use:   "remove-poa-validator",
 	Short: "Remove PoA validator",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+		subnetID, err := helpers.LoadSubnetID()
 		if err != nil {
 			return fmt.Errorf("failed to load subnet ID: %w", err)
 		}
 
-		validatorsResp, err := callPChainValidatorsAt("https://api.avax-test.network/ext/P", subnetID.String())
+		validatorsResp, err := callPChainValidatorsAt(currentNetwork.PChainEndpoint(), subnetID.String())
 		if err != nil {
 			return fmt.Errorf("failed to get validators: %w", err)
 		}
@@ -59,6 +58,10 @@ var removeValidatorStep1Cmd = &cobra.Command{
 			log.Fatalf("failed to parse node ID: %s", err)
 		}
 
+		if err := checkRemoveValidatorFunds(); err != nil {
+			return err
+		}
+
 		signedMessage, validationID, err := InitValidatorRemoval(nodeID)
 		if err != nil {
 			return fmt.Errorf("failed to initialize validator removal: %w", err)
@@ -85,18 +88,29 @@ var removeValidatorStep1Cmd = &cobra.Command{
 			return fmt.Errorf("failed to finish validator removal: %w", err)
 		}
 
-		return nil
+		return helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			validator, _ := manifest.Validator(nodeID)
+			validator.NodeID = nodeID
+			validator.ValidationID = validationID
+			validator.Removed = true
+			manifest.SetValidator(validator)
+			return nil
+		})
 	},
 }
 
 func InitValidatorRemoval(nodeId ids.NodeID) (*warp.Message, ids.ID, error) {
-	chainID, err := helpers.LoadId(helpers.ChainIdPath)
+	chainID, err := helpers.LoadChainID()
 	if err != nil {
 		return nil, ids.Empty, fmt.Errorf("failed to load chain ID: %w", err)
 	}
-	nodeURL := fmt.Sprintf("http://%s:%s/ext/bc/%s/rpc", "127.0.0.1", "9650", chainID)
+	nodeURL, err := helpers.NodeRPCURL(0, chainID)
+	if err != nil {
+		return nil, ids.Empty, err
+	}
 
-	privateKey, err := helpers.LoadSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath)
+	// Only the owner of the PoA manager can remove validators
+	signer, err := roleSigner(helpers.RoleManagerOwner)
 	if err != nil {
 		return nil, ids.Empty, fmt.Errorf("failed to load private key: %w", err)
 	}
@@ -108,9 +122,9 @@ func InitValidatorRemoval(nodeId ids.NodeID) (*warp.Message, ids.ID, error) {
 		return nil, ids.Empty, fmt.Errorf("failed to get registered validator: %w", err)
 	}
 
-	tx, _, err := contract.TxToMethod(
+	tx, _, err := txToMethod(
 		nodeURL,
-		hex.EncodeToString(privateKey.Bytes()),
+		signer,
 		managerAddress,
 		big.NewInt(0),
 		"POA validator removal initialization",
@@ -125,21 +139,21 @@ func InitValidatorRemoval(nodeId ids.NodeID) (*warp.Message, ids.ID, error) {
 		ux.Logger.PrintToUser("the validator removal process was already initialized. Proceeding to the next step")
 	}
 
-	network := models.NewFujiNetwork()
+	network := aggregatorNetwork()
 	aggregatorLogLevel := logging.Level(logging.Info)
 	aggregatorQuorumPercentage := uint64(0)
 	aggregatorAllowPrivateIPs := true
-	aggregatorExtraPeerEndpoints, err := blockchaincmd.ConvertURIToPeers([]string{"http://127.0.0.1:9650"})
+	aggregatorExtraPeerEndpoints, err := aggregatorPeers()
 	if err != nil {
-		return nil, ids.Empty, fmt.Errorf("failed to get extra peers: %w", err)
+		return nil, ids.Empty, err
 	}
 
-	subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+	subnetID, err := helpers.LoadSubnetID()
 	if err != nil {
 		return nil, ids.Empty, fmt.Errorf("failed to load subnet ID: %w", err)
 	}
 
-	blockchainID, err := helpers.LoadId(helpers.ChainIdPath)
+	blockchainID, err := helpers.LoadChainID()
 	if err != nil {
 		return nil, ids.Empty, fmt.Errorf("failed to load blockchain ID: %w", err)
 	}
@@ -5,10 +5,7 @@ import (
 	"log"
 	"math/big"
 
-	"github.com/ava-labs/avalanche-cli/cmd/blockchaincmd"
-	"github.com/ava-labs/avalanche-cli/pkg/contract"
 	"github.com/ava-labs/avalanche-cli/pkg/evm"
-	"github.com/ava-labs/avalanche-cli/pkg/models"
 	validatorManagerSDK "github.com/ava-labs/avalanche-cli/sdk/validatormanager"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -24,23 +21,26 @@ import (
 //- CompleteValidatorRemoval
 
 func FinishValidatorRemoval(validationID ids.ID) error {
-	chainID, err := helpers.LoadId(helpers.ChainIdPath)
+	chainID, err := helpers.LoadChainID()
 	if err != nil {
 		return fmt.Errorf("failed to load chain ID: %w", err)
 	}
-	rpcURL := fmt.Sprintf("http://%s:%s/ext/bc/%s/rpc", "127.0.0.1", "9650", chainID)
+	rpcURL, err := helpers.NodeRPCURL(0, chainID)
+	if err != nil {
+		return err
+	}
 
-	network := models.NewFujiNetwork()
+	network := aggregatorNetwork()
 	aggregatorLogLevel := logging.Level(logging.Info)
 	aggregatorAllowPrivateIPs := true
 	aggregatorQuorumPercentage := uint64(0)
-	subnetID, err := helpers.LoadId(helpers.SubnetIdPath)
+	subnetID, err := helpers.LoadSubnetID()
 	if err != nil {
 		return fmt.Errorf("failed to load subnet id: %w", err)
 	}
-	aggregatorExtraPeerEndpoints, err := blockchaincmd.ConvertURIToPeers([]string{"http://127.0.0.1:9650"})
+	aggregatorExtraPeerEndpoints, err := aggregatorPeers()
 	if err != nil {
-		return fmt.Errorf("failed to get extra peers: %w", err)
+		return err
 	}
 	registered := false
 
@@ -59,23 +59,23 @@ func FinishValidatorRemoval(validationID ids.ID) error {
 		log.Fatalf("failed to get P-chain subnet validator registration warp message: %s", err)
 	}
 
-	privateKey, err := helpers.LoadText(helpers.ValidatorManagerOwnerKeyPath)
+	signer, err := ownerSigner()
 	if err != nil {
 		return fmt.Errorf("failed to load manager key: %w", err)
 	}
 
-	if err := evm.SetupProposerVM(
+	if err := setupProposerVM(
 		rpcURL,
-		privateKey,
+		signer,
 	); err != nil {
 		return err
 	}
 
 	managerAddress := goethereumcommon.HexToAddress(config.ProxyContractAddress)
 
-	tx, _, err := contract.TxToMethodWithWarpMessage(
+	tx, _, err := txToMethodWithWarpMessage(
 		rpcURL,
-		privateKey,
+		signer,
 		managerAddress,
 		signedMessage,
 		big.NewInt(0),
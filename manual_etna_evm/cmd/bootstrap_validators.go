@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanche-cli/pkg/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+var convertBootstrapValidators []string
+
+// bootstrapValidatorSpec is one --bootstrap-validator of convert-to-L1
+type bootstrapValidatorSpec struct {
+	// credsFolder holds the staking keys of a node this workspace can read,
+	// uri is where the node answers, one of them is always set
+	credsFolder string
+	uri         string
+	weight      uint64
+	balance     uint64
+	// changeOwner gets the remaining balance when the validator leaves,
+	// empty for the validator balance owner role
+	changeOwner ids.ShortID
+}
+
+// parseBootstrapValidators reads the --bootstrap-validator values, which
+// look like <creds folder|http(s)://node>[,weight=N][,balance=AVAX][,change-owner=P-...][,uri=http(s)://node].
+// Without any, node0 is the only bootstrap validator.
+func parseBootstrapValidators(values []string, defaultBalance uint64) ([]bootstrapValidatorSpec, error) {
+	if len(values) == 0 {
+		values = []string{helpers.Node0KeysFolder}
+	}
+	specs := make([]bootstrapValidatorSpec, len(values))
+	for i, value := range values {
+		parts := strings.Split(value, ",")
+		spec := bootstrapValidatorSpec{
+			weight:  constants.BootstrapValidatorWeight,
+			balance: defaultBalance,
+		}
+		if isNodeURI(parts[0]) {
+			spec.uri = parts[0]
+		} else {
+			spec.credsFolder = parts[0]
+		}
+		for _, option := range parts[1:] {
+			key, optionValue, ok := strings.Cut(option, "=")
+			if !ok {
+				return nil, fmt.Errorf("--bootstrap-validator %q: %q must look like key=value", value, option)
+			}
+			var err error
+			switch key {
+			case "weight":
+				spec.weight, err = strconv.ParseUint(optionValue, 10, 64)
+				if err == nil && spec.weight == 0 {
+					err = fmt.Errorf("weight must be positive")
+				}
+			case "balance":
+				spec.balance, err = parseAVAXAmount(optionValue)
+			case "change-owner":
+				spec.changeOwner, err = address.ParseToID(optionValue)
+			case "uri":
+				if !isNodeURI(optionValue) {
+					err = fmt.Errorf("%s is not an http(s) URI", optionValue)
+				}
+				spec.uri = optionValue
+			default:
+				err = fmt.Errorf("unknown option %s, must be one of weight, balance, change-owner, uri", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("--bootstrap-validator %q: %w", value, err)
+			}
+		}
+		specs[i] = spec
+	}
+	return specs, nil
+}
+
+func isNodeURI(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+// bootstrapBalances is the balance each bootstrap validator starts with
+func bootstrapBalances(specs []bootstrapValidatorSpec) []uint64 {
+	balances := make([]uint64, len(specs))
+	for i, spec := range specs {
+		balances[i] = spec.balance
+	}
+	return balances
+}
+
+// nodeInfo reads the node ID and BLS key from the creds folder, or asks the
+// node through info.getNodeID
+func (s bootstrapValidatorSpec) nodeInfo() (ids.NodeID, *signer.ProofOfPossession, error) {
+	if s.credsFolder != "" {
+		nodeID, pop, err := NodeInfoFromCreds(s.credsFolder)
+		if err != nil {
+			return ids.NodeID{}, nil, fmt.Errorf("failed to get node info from %s: %w", s.credsFolder, err)
+		}
+		return nodeID, pop, nil
+	}
+	nodeID, pop, err := helpers.GetNodeInfoRetry(s.uri)
+	if err != nil {
+		return ids.NodeID{}, nil, fmt.Errorf("failed to get node info from %s: %w", s.uri, err)
+	}
+	return nodeID, pop, nil
+}
+
+// convertValidators resolves specs into the validators of a
+// ConvertSubnetToL1Tx, sorted by node ID like the P-chain requires, and
+// returns the spec each node came from
+func convertValidators(specs []bootstrapValidatorSpec, balanceOwner ids.ShortID, disableOwner ids.ShortID) ([]*txs.ConvertSubnetToL1Validator, map[ids.NodeID]bootstrapValidatorSpec, error) {
+	validators := make([]*txs.ConvertSubnetToL1Validator, len(specs))
+	sources := map[ids.NodeID]bootstrapValidatorSpec{}
+	for i, spec := range specs {
+		nodeID, pop, err := spec.nodeInfo()
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := sources[nodeID]; ok {
+			return nil, nil, fmt.Errorf("node %s is given twice as a bootstrap validator", nodeID)
+		}
+		sources[nodeID] = spec
+
+		changeOwner := spec.changeOwner
+		if changeOwner == ids.ShortEmpty {
+			changeOwner = balanceOwner
+		}
+		validators[i] = &txs.ConvertSubnetToL1Validator{
+			NodeID:                nodeID.Bytes(),
+			Weight:                spec.weight,
+			Balance:               spec.balance,
+			Signer:                *pop,
+			RemainingBalanceOwner: message.PChainOwner{Threshold: 1, Addresses: []ids.ShortID{changeOwner}},
+			DeactivationOwner:     message.PChainOwner{Threshold: 1, Addresses: []ids.ShortID{disableOwner}},
+		}
+	}
+	utils.Sort(validators)
+	return validators, sources, nil
+}
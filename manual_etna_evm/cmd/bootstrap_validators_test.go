@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/avalanche-cli/pkg/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+func TestParseBootstrapValidators(t *testing.T) {
+	changeOwner := ids.GenerateTestShortID()
+	changeOwnerAddr, err := address.Format("P", "fuji", changeOwner.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultBalance := units.Avax
+
+	tests := []struct {
+		name   string
+		values []string
+		want   []bootstrapValidatorSpec
+	}{
+		{"node0 by default", nil, []bootstrapValidatorSpec{
+			{credsFolder: helpers.Node0KeysFolder, weight: constants.BootstrapValidatorWeight, balance: defaultBalance},
+		}},
+		{"creds folder with options", []string{"data/node1,weight=20,balance=2.5,change-owner=" + changeOwnerAddr + ",uri=http://10.0.0.1:9650"}, []bootstrapValidatorSpec{
+			{credsFolder: "data/node1", uri: "http://10.0.0.1:9650", weight: 20, balance: 2500 * units.MilliAvax, changeOwner: changeOwner},
+		}},
+		{"node URI", []string{"https://node.example:9650", "http://10.0.0.2:9650,weight=5"}, []bootstrapValidatorSpec{
+			{uri: "https://node.example:9650", weight: constants.BootstrapValidatorWeight, balance: defaultBalance},
+			{uri: "http://10.0.0.2:9650", weight: 5, balance: defaultBalance},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			specs, err := parseBootstrapValidators(test.values, defaultBalance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(specs, test.want) {
+				t.Fatalf("parsed %+v, want %+v", specs, test.want)
+			}
+		})
+	}
+
+	if balances := bootstrapBalances(tests[2].want); !reflect.DeepEqual(balances, []uint64{defaultBalance, defaultBalance}) {
+		t.Fatalf("balances are %v", balances)
+	}
+
+	for _, value := range []string{
+		"data/node1,weight",
+		"data/node1,weight=0",
+		"data/node1,weight=-1",
+		"data/node1,balance=0",
+		"data/node1,balance=1.0000000001",
+		"data/node1,change-owner=nobody",
+		"data/node1,uri=10.0.0.1:9650",
+		"data/node1,stake=1",
+	} {
+		if specs, err := parseBootstrapValidators([]string{value}, defaultBalance); err == nil {
+			t.Fatalf("%q was parsed as %+v", value, specs)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// verifiedConversionData returns the conversion data recorded by
+// convert-to-L1 once its conversion ID, computed here, matches the one the
+// P-chain reports for the subnet. Manifests from before the data was
+// recorded get it from the conversion tx.
+func verifiedConversionData(ctx context.Context, pClient platformvm.Client, manifest *helpers.Manifest) (*helpers.ConversionData, ids.ID, error) {
+	if manifest.ConversionTxID == ids.Empty {
+		return nil, ids.Empty, fmt.Errorf("no conversion is recorded in the manifest, run convert-to-L1 first")
+	}
+	data := manifest.ConversionData
+	if data == nil {
+		var err error
+		data, err = pChainConversionData(ctx, pClient, manifest.ConversionTxID)
+		if err != nil {
+			return nil, ids.Empty, err
+		}
+	}
+
+	// What the steps after the conversion expect
+	expected := []string{}
+	if data.SubnetID != manifest.SubnetID {
+		expected = append(expected, fmt.Sprintf("subnet: converted %s, workspace has %s", data.SubnetID, manifest.SubnetID))
+	}
+	if data.ManagerChainID != manifest.ChainID {
+		expected = append(expected, fmt.Sprintf("manager chain: converted with %s, workspace has %s", data.ManagerChainID, manifest.ChainID))
+	}
+	managerAddress := common.HexToAddress(config.ProxyContractAddress)
+	if !bytes.Equal(data.ManagerAddress, managerAddress.Bytes()) {
+		expected = append(expected, fmt.Sprintf("manager address: converted with %s, the validator manager proxy is %s", data.ManagerAddress, managerAddress))
+	}
+	if len(expected) > 0 {
+		return nil, ids.Empty, fmt.Errorf("conversion tx %s doesn't match the workspace:\n  %s", manifest.ConversionTxID, strings.Join(expected, "\n  "))
+	}
+
+	conversionID, err := data.ID()
+	if err != nil {
+		return nil, ids.Empty, err
+	}
+	subnet, err := pClient.GetSubnet(ctx, data.SubnetID)
+	if err != nil {
+		return nil, ids.Empty, fmt.Errorf("failed to get subnet %s: %w", data.SubnetID, err)
+	}
+	if subnet.ConversionID == ids.Empty {
+		return nil, ids.Empty, fmt.Errorf("subnet %s is not converted on the P-chain, conversion tx %s is recorded", data.SubnetID, manifest.ConversionTxID)
+	}
+	if subnet.ConversionID != conversionID {
+		return nil, ids.Empty, conversionMismatchError(ctx, pClient, manifest, data, conversionID, subnet)
+	}
+	if manifest.ConversionID != ids.Empty && manifest.ConversionID != conversionID {
+		return nil, ids.Empty, fmt.Errorf("manifest has conversion ID %s, the recorded conversion data hashes to %s", manifest.ConversionID, conversionID)
+	}
+
+	if manifest.ConversionData == nil || manifest.ConversionID == ids.Empty {
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			manifest.ConversionID = conversionID
+			manifest.ConversionData = data
+			return nil
+		})
+		if err != nil {
+			return nil, ids.Empty, fmt.Errorf("failed to save conversion data: %w", err)
+		}
+	}
+	return data, conversionID, nil
+}
+
+// pChainConversionData reads the conversion data of a ConvertSubnetToL1Tx
+// from the P-chain
+func pChainConversionData(ctx context.Context, pClient platformvm.Client, txID ids.ID) (*helpers.ConversionData, error) {
+	txBytes, err := pClient.GetTx(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversion tx %s: %w", txID, err)
+	}
+	tx, err := txs.Parse(txs.Codec, txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tx %s: %w", txID, err)
+	}
+	convertTx, ok := tx.Unsigned.(*txs.ConvertSubnetToL1Tx)
+	if !ok {
+		return nil, fmt.Errorf("tx %s is a %T, not a ConvertSubnetToL1Tx", txID, tx.Unsigned)
+	}
+	return helpers.ConversionDataFromTx(convertTx), nil
+}
+
+// conversionMismatchError explains how the recorded conversion data differs
+// from what the P-chain hashed, using the recorded conversion tx when it is
+// the one that converted the subnet
+func conversionMismatchError(ctx context.Context, pClient platformvm.Client, manifest *helpers.Manifest, data *helpers.ConversionData, conversionID ids.ID, subnet platformvm.GetSubnetClientResponse) error {
+	header := fmt.Sprintf("conversion ID %s computed from the recorded conversion data doesn't match %s reported by the P-chain for subnet %s", conversionID, subnet.ConversionID, data.SubnetID)
+
+	diffs := []string{}
+	pChainData, err := pChainConversionData(ctx, pClient, manifest.ConversionTxID)
+	if err == nil {
+		pChainID, err := pChainData.ID()
+		if err == nil && pChainID == subnet.ConversionID {
+			diffs = diffConversionData(data, pChainData)
+		}
+	}
+	if len(diffs) == 0 {
+		// The subnet was converted by another tx, only the manager is known
+		if subnet.ManagerChainID != data.ManagerChainID {
+			diffs = append(diffs, fmt.Sprintf("manager chain: recorded %s, P-chain %s", data.ManagerChainID, subnet.ManagerChainID))
+		}
+		if !bytes.Equal(subnet.ManagerAddress, data.ManagerAddress) {
+			diffs = append(diffs, fmt.Sprintf("manager address: recorded %s, P-chain %s", data.ManagerAddress, hexutil.Bytes(subnet.ManagerAddress)))
+		}
+		diffs = append(diffs, fmt.Sprintf("the subnet wasn't converted by the recorded tx %s, so its validators can't be compared", manifest.ConversionTxID))
+	}
+	return fmt.Errorf("%s:\n  %s", header, strings.Join(diffs, "\n  "))
+}
+
+// diffConversionData lists what differs between the recorded conversion data
+// and the one the P-chain hashed: subnet, manager, validator order, and the
+// weight and BLS key of each validator
+func diffConversionData(recorded *helpers.ConversionData, pChain *helpers.ConversionData) []string {
+	diffs := []string{}
+	if recorded.SubnetID != pChain.SubnetID {
+		diffs = append(diffs, fmt.Sprintf("subnet: recorded %s, P-chain %s", recorded.SubnetID, pChain.SubnetID))
+	}
+	if recorded.ManagerChainID != pChain.ManagerChainID {
+		diffs = append(diffs, fmt.Sprintf("manager chain: recorded %s, P-chain %s", recorded.ManagerChainID, pChain.ManagerChainID))
+	}
+	if !bytes.Equal(recorded.ManagerAddress, pChain.ManagerAddress) {
+		diffs = append(diffs, fmt.Sprintf("manager address: recorded %s, P-chain %s", recorded.ManagerAddress, pChain.ManagerAddress))
+	}
+
+	pChainIndex := map[string]int{}
+	for i, validator := range pChain.Validators {
+		pChainIndex[string(validator.NodeID)] = i
+	}
+	recordedNodes := map[string]bool{}
+	reordered := false
+	for i, validator := range recorded.Validators {
+		recordedNodes[string(validator.NodeID)] = true
+		j, ok := pChainIndex[string(validator.NodeID)]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("validator %d %s: recorded, not on the P-chain", i, conversionNodeID(validator.NodeID)))
+			continue
+		}
+		reordered = reordered || i != j
+		if validator.Weight != pChain.Validators[j].Weight {
+			diffs = append(diffs, fmt.Sprintf("validator %s weight: recorded %d, P-chain %d", conversionNodeID(validator.NodeID), validator.Weight, pChain.Validators[j].Weight))
+		}
+		if !bytes.Equal(validator.BLSPublicKey, pChain.Validators[j].BLSPublicKey) {
+			diffs = append(diffs, fmt.Sprintf("validator %s BLS key: recorded %s, P-chain %s", conversionNodeID(validator.NodeID), validator.BLSPublicKey, pChain.Validators[j].BLSPublicKey))
+		}
+	}
+	for j, validator := range pChain.Validators {
+		if !recordedNodes[string(validator.NodeID)] {
+			diffs = append(diffs, fmt.Sprintf("validator %d %s: on the P-chain, not recorded", j, conversionNodeID(validator.NodeID)))
+		}
+	}
+	if reordered {
+		diffs = append(diffs, fmt.Sprintf("validator order: recorded %s, P-chain %s", conversionNodeIDs(recorded.Validators), conversionNodeIDs(pChain.Validators)))
+	}
+	return diffs
+}
+
+func conversionNodeID(nodeID []byte) string {
+	id, err := ids.ToNodeID(nodeID)
+	if err != nil {
+		return hexutil.Encode(nodeID)
+	}
+	return id.String()
+}
+
+func conversionNodeIDs(validators []helpers.ConversionValidator) string {
+	nodeIDs := make([]string, len(validators))
+	for i, validator := range validators {
+		nodeIDs[i] = conversionNodeID(validator.NodeID)
+	}
+	return "[" + strings.Join(nodeIDs, ", ") + "]"
+}
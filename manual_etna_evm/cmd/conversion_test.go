@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+func testConversionData() *helpers.ConversionData {
+	validators := make([]helpers.ConversionValidator, 3)
+	for i := range validators {
+		nodeID := ids.GenerateTestNodeID()
+		validators[i] = helpers.ConversionValidator{
+			NodeID:       nodeID.Bytes(),
+			BLSPublicKey: make([]byte, 48),
+			Weight:       100,
+		}
+		validators[i].BLSPublicKey[0] = byte(i)
+	}
+	return &helpers.ConversionData{
+		SubnetID:       ids.GenerateTestID(),
+		ManagerChainID: ids.GenerateTestID(),
+		ManagerAddress: []byte{0xfe, 0xed},
+		Validators:     validators,
+	}
+}
+
+func copyConversionData(data *helpers.ConversionData) *helpers.ConversionData {
+	copied := *data
+	copied.Validators = append([]helpers.ConversionValidator{}, data.Validators...)
+	return &copied
+}
+
+func TestDiffConversionData(t *testing.T) {
+	recorded := testConversionData()
+	if diffs := diffConversionData(recorded, copyConversionData(recorded)); len(diffs) != 0 {
+		t.Fatalf("identical data differs: %v", diffs)
+	}
+
+	tests := []struct {
+		name   string
+		change func(pChain *helpers.ConversionData)
+		want   []string
+	}{
+		{"manager address", func(pChain *helpers.ConversionData) { pChain.ManagerAddress = []byte{0xbe, 0xef} }, []string{"manager address: recorded 0xfeed, P-chain 0xbeef"}},
+		{"weight", func(pChain *helpers.ConversionData) { pChain.Validators[1].Weight = 50 }, []string{"weight: recorded 100, P-chain 50"}},
+		{"BLS key", func(pChain *helpers.ConversionData) {
+			pChain.Validators[2].BLSPublicKey = make([]byte, 48)
+		}, []string{"BLS key"}},
+		{"order", func(pChain *helpers.ConversionData) {
+			pChain.Validators[0], pChain.Validators[1] = pChain.Validators[1], pChain.Validators[0]
+		}, []string{"validator order"}},
+		{"missing and extra validator", func(pChain *helpers.ConversionData) {
+			pChain.Validators[2].NodeID = ids.GenerateTestNodeID().Bytes()
+		}, []string{"recorded, not on the P-chain", "on the P-chain, not recorded"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pChain := copyConversionData(recorded)
+			test.change(pChain)
+			diffs := diffConversionData(recorded, pChain)
+			if len(diffs) != len(test.want) {
+				t.Fatalf("diffs are %v, want %d", diffs, len(test.want))
+			}
+			for i, want := range test.want {
+				if !strings.Contains(diffs[i], want) {
+					t.Fatalf("diff %q doesn't mention %q", diffs[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addUnsignedOutFlag(disableValidatorCmd)
+	rootCmd.AddCommand(disableValidatorCmd)
+}
+
+var disableValidatorCmd = &cobra.Command{
+	Use:   "disable-validator <NodeID|ValidationID>",
+	Short: "Disable an L1 validator on the P-chain and reclaim its balance",
+	Long: `Disable an L1 validator on the P-chain and reclaim its balance.
+
+The disable owner given to the validator when it was registered signs a
+DisableL1ValidatorTx, without going through the validator manager contract.
+The validator stops validating and what is left of its balance is refunded
+to its remaining balance owner. It stays in the validator set of the
+contract, so remove it there too once the contract cooperates. Use this for
+a misbehaving validator that has to go now.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		PrintHeader("🛑 Disabling L1 validator")
+
+		validationID, err := resolveValidationID(args[0])
+		if err != nil {
+			return err
+		}
+		pClient := platformvm.NewClient(currentNetwork.PChainURI)
+		validator, _, err := pClient.GetL1Validator(context.Background(), validationID)
+		if err != nil {
+			return fmt.Errorf("failed to get L1 validator %s: %w", validationID, err)
+		}
+		if validator.Balance == 0 {
+			log.Printf("✅ Validator %s is already inactive, there is no balance to reclaim\n", validator.NodeID)
+			return nil
+		}
+		log.Printf("Validator %s of subnet %s has %s AVAX left\n", validator.NodeID, validator.SubnetID, formatAVAX(validator.Balance))
+
+		disableOwner, err := roleAddress(helpers.RoleValidatorDisableOwner)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(validator.DeactivationOwner.Addrs, disableOwner) {
+			owners, err := formatPChainAddresses(currentNetwork.NetworkID, validator.DeactivationOwner.Addrs)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("validator %s can only be disabled by %v, not by the disable owner of this workspace", validator.NodeID, owners)
+		}
+		if unsignedOutPath == "" {
+			_, err := roleSigner(helpers.RoleValidatorDisableOwner)
+			if errors.Is(err, errRoleWithoutKey) {
+				return fmt.Errorf("%w: save the tx with --unsigned-out and sign it where the key is", err)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := checkDisableValidatorFunds(validator.DeactivationOwner); err != nil {
+			return err
+		}
+
+		kc, _, err := pChainKeychain(helpers.RoleFeePayer, helpers.RoleValidatorDisableOwner)
+		if err != nil {
+			return err
+		}
+		wallet, err := pChainWallet(kc, nil, []ids.ID{validationID})
+		if err != nil {
+			return err
+		}
+
+		unsignedTx, err := wallet.Builder().NewDisableL1ValidatorTx(validationID)
+		if err != nil {
+			return fmt.Errorf("failed to build disable validator tx: %w", err)
+		}
+		tx, err := issuePChainTx(wallet, unsignedTx)
+		if err != nil {
+			return err
+		}
+		if tx == nil {
+			return nil
+		}
+		log.Printf("✅ Disabled validator %s in tx %s\n", validator.NodeID, tx.ID())
+		return recordPChainTx(tx)
+	},
+}
+
+// reportDisabledBalance logs the refund a committed DisableL1ValidatorTx sent
+// to the remaining balance owner. The P-chain adds it as the output after
+// those of the tx.
+func reportDisabledBalance(tx *txs.Tx, utx *txs.DisableL1ValidatorTx) error {
+	ctx := context.Background()
+	pClient := platformvm.NewClient(currentNetwork.PChainURI)
+	validator, _, err := pClient.GetL1Validator(ctx, utx.ValidationID)
+	if err != nil {
+		return fmt.Errorf("failed to get L1 validator %s: %w", utx.ValidationID, err)
+	}
+	owners, err := formatPChainAddresses(currentNetwork.NetworkID, validator.RemainingBalanceOwner.Addrs)
+	if err != nil {
+		return err
+	}
+
+	state, err := primary.FetchState(ctx, currentNetwork.PChainURI, set.Of(validator.RemainingBalanceOwner.Addrs...))
+	if err != nil {
+		return fmt.Errorf("failed to fetch P-chain state: %w", err)
+	}
+	refundID := avax.UTXOID{TxID: tx.ID(), OutputIndex: uint32(len(utx.Outs))}
+	refund, err := state.UTXOs.GetUTXO(ctx, avagoconstants.PlatformChainID, avagoconstants.PlatformChainID, refundID.InputID())
+	if errors.Is(err, database.ErrNotFound) {
+		log.Printf("No refund to %v left from tx %s, it was already spent or the validator had no balance\n", owners, tx.ID())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get refund UTXO: %w", err)
+	}
+	out, ok := refund.Out.(*secp256k1fx.TransferOutput)
+	if !ok {
+		return fmt.Errorf("expected a transfer output as refund, got %T", refund.Out)
+	}
+	log.Printf("✅ Refunded %s AVAX of remaining balance to %v\n", formatAVAX(out.Amt), owners)
+	return nil
+}
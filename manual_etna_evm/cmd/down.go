@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	downKeepKeys   bool
+	downKeepNodeDB bool
+	downPurge      bool
+)
+
+func init() {
+	downCmd.Flags().BoolVar(&downKeepKeys, "keep-keys", true, "Keep the validator manager owner key and all node credentials")
+	downCmd.Flags().BoolVar(&downKeepNodeDB, "keep-node-db", true, "Keep the avalanchego database of node0 so it doesn't have to sync again")
+	downCmd.Flags().BoolVar(&downPurge, "purge", false, "Remove everything, same as --keep-keys=false --keep-node-db=false")
+	rootCmd.AddCommand(downCmd)
+}
+
+var downCmd = &cobra.Command{
+	Use:         "down",
+	Annotations: offline,
+	Short:       "Stop the nodes of the workspace and delete its state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		PrintHeader("🧹 Tearing down workspace " + helpers.CurrentWorkspace)
+
+		if downPurge {
+			if (cmd.Flags().Changed("keep-keys") && downKeepKeys) || (cmd.Flags().Changed("keep-node-db") && downKeepNodeDB) {
+				return fmt.Errorf("--purge can't be combined with --keep-keys or --keep-node-db")
+			}
+			downKeepKeys = false
+			downKeepNodeDB = false
+		}
+
+		if err := removeWorkspaceContainers(helpers.CurrentWorkspace); err != nil {
+			return err
+		}
+
+		removed, err := helpers.ResetWorkspaceData(downKeepKeys, downKeepNodeDB)
+		if err != nil {
+			return fmt.Errorf("failed to delete workspace state: %w", err)
+		}
+		for _, path := range removed {
+			log.Printf("- Removed %s\n", path)
+		}
+		log.Printf("- Reset %s\n", helpers.ManifestPath)
+
+		if downKeepKeys {
+			log.Println("Kept keys and node credentials")
+		}
+		if downKeepNodeDB {
+			log.Println("Kept the node0 database")
+		}
+		log.Println("✅ Teardown completed")
+		return nil
+	},
+}
+
+// removeWorkspaceContainers stops and removes every container of workspace name
+func removeWorkspaceContainers(name string) error {
+	containers, err := workspaceContainers(name)
+	if err != nil {
+		return err
+	}
+	for _, container := range containers {
+		output, err := exec.Command("docker", "rm", "-f", container).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to remove container %s: %w\n%s", container, err, output)
+		}
+		log.Printf("- Removed container %s\n", container)
+	}
+	if len(containers) == 0 {
+		log.Printf("- No containers labeled %s=%s\n", helpers.ContainerLabel, name)
+	}
+	return nil
+}
+
+// workspaceContainers lists the containers, running or not, started for workspace name
+func workspaceContainers(name string) ([]string, error) {
+	output, err := exec.Command("docker", "ps", "-a", "--filter", helpers.ContainerLabelFilter(name), "--format", "{{.Names}}").CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		// Without docker no container was ever started
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w\n%s", err, output)
+	}
+	return strings.Fields(string(output)), nil
+}
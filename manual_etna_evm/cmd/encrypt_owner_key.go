@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(encryptOwnerKeyCmd)
+}
+
+var encryptOwnerKeyCmd = &cobra.Command{
+	Use:         "encrypt-owner-key",
+	Annotations: offline,
+	Short:       "Move a plaintext validator manager owner key into an encrypted keystore",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		PrintHeader("🔐 Encrypting validator manager owner key")
+
+		encrypted := helpers.NewKeystoreKeyProvider(helpers.ValidatorManagerOwnerKeystorePath)
+		exists, err := encrypted.Exists()
+		if err != nil {
+			return fmt.Errorf("failed to check if keystore exists: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("keystore %s already exists", encrypted.Path())
+		}
+
+		plaintext := helpers.NewPlaintextKeyProvider(helpers.ValidatorManagerOwnerKeyPath)
+		key, err := plaintext.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load plaintext key: %w", err)
+		}
+		if err := encrypted.Save(key); err != nil {
+			return fmt.Errorf("failed to save keystore: %w", err)
+		}
+
+		// Make sure the keystore can be unlocked before the only other copy is gone
+		decrypted, err := encrypted.Load()
+		if err != nil {
+			return fmt.Errorf("failed to verify keystore: %w", err)
+		}
+		if decrypted.Address() != key.Address() {
+			return fmt.Errorf("keystore %s doesn't hold the original key", encrypted.Path())
+		}
+		if err := os.Remove(plaintext.Path()); err != nil {
+			return fmt.Errorf("failed to remove plaintext key: %w", err)
+		}
+
+		log.Printf("✅ Saved the key to %s and removed %s\n", encrypted.Path(), plaintext.Path())
+		return nil
+	},
+}
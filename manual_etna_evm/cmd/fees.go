@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+	"github.com/ava-labs/avalanchego/wallet/chain/p/wallet"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"github.com/ava-labs/coreth/ethclient"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+var estimateFeesValidators int
+
+func init() {
+	estimateFeesCmd.Flags().IntVar(&estimateFeesValidators, "validators", 1, "Number of bootstrap validators to convert the subnet with")
+	estimateFeesCmd.Flags().BoolVar(&managerInGenesis, "manager-in-genesis", false, "Size create-chain for a genesis embedding the --validator-type manager, if it wasn't generated yet")
+	estimateFeesCmd.Flags().StringVar(&validatorType, "validator-type", "", fmt.Sprintf("Type of validator manager embedded with --manager-in-genesis (%s or %s)", config.PoAMode, config.PoSNativeMode))
+	estimateFeesCmd.Flags().StringVar(&genesisSpecPath, "spec", "", "Genesis spec create-chain is sized for, if the genesis wasn't generated yet (see generate-genesis --help)")
+	rootCmd.AddCommand(estimateFeesCmd)
+}
+
+var estimateFeesCmd = &cobra.Command{
+	Use:   "estimate-fees",
+	Short: "Estimate the P-chain fees of every operation at the current gas price",
+	Long: `Estimate the P-chain fees of every operation at the current gas price.
+
+Each tx is built as the real command would build it, without being issued,
+and compared with the P-chain balance of the fee payer. Amounts handed to
+validators as their continuous fee balance are counted separately.
+
+create-chain carries the genesis of the workspace. If it wasn't generated
+yet, the genesis generate-genesis would write with the same --spec,
+--manager-in-genesis and --validator-type is built without being saved.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		PrintHeader("🧮 Estimating P-chain fees")
+
+		if estimateFeesValidators < 1 {
+			return fmt.Errorf("--validators must be at least 1, got %d", estimateFeesValidators)
+		}
+		if managerInGenesis && validatorType == "" {
+			return fmt.Errorf("--manager-in-genesis needs --validator-type %s or %s", config.PoAMode, config.PoSNativeMode)
+		}
+		estimator, balance, err := pChainFeeEstimator()
+		if err != nil {
+			return err
+		}
+
+		bootstrapBalance, err := validatorBalance(convertValidatorBalance)
+		if err != nil {
+			return err
+		}
+		addBalance, err := validatorBalance(addValidatorBalance)
+		if err != nil {
+			return err
+		}
+
+		bootstrapBalances := make([]uint64, estimateFeesValidators)
+		for i := range bootstrapBalances {
+			bootstrapBalances[i] = bootstrapBalance
+		}
+		setup, err := setupFeeEstimates(estimator, bootstrapBalances, false)
+		if err != nil {
+			return err
+		}
+		log.Println("L1 setup:")
+		printFeeEstimates(setup, balance)
+
+		signers := estimateFeesValidators
+		register, err := estimator.RegisterL1Validator(addBalance, signers)
+		if err != nil {
+			return err
+		}
+		log.Println("add-poa-validator:")
+		printFeeEstimates([]helpers.FeeEstimate{register}, balance)
+
+		setWeight, err := estimator.SetL1ValidatorWeight(signers)
+		if err != nil {
+			return err
+		}
+		log.Println("remove-poa-validator:")
+		printFeeEstimates([]helpers.FeeEstimate{setWeight}, balance)
+		return nil
+	},
+}
+
+// pChainFeeEstimator returns an estimator at the current gas price and the
+// P-chain balance of the fee payer
+func pChainFeeEstimator() (*helpers.FeeEstimator, uint64, error) {
+	feePayer, err := roleAddress(helpers.RoleFeePayer)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	addrs := set.Of(feePayer)
+	state, err := primary.FetchState(context.Background(), currentNetwork.PChainURI, addrs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch P-chain state: %w", err)
+	}
+	pUTXOs := common.NewChainUTXOs(avagoconstants.PlatformChainID, state.UTXOs)
+	pBuilder := builder.New(addrs, state.PCTX, wallet.NewBackend(state.PCTX, pUTXOs, nil))
+	balances, err := pBuilder.GetBalance()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get P-chain balance: %w", err)
+	}
+	return helpers.NewFeeEstimator(state.PCTX, feePayer), balances[state.PCTX.AVAXAssetID], nil
+}
+
+// setupFeeEstimates estimates create-subnet, create-chain and convert-to-L1
+// with one bootstrap validator per entry of balances. create-chain carries a
+// new genesis if regenerateGenesis is set, see plannedGenesis.
+func setupFeeEstimates(estimator *helpers.FeeEstimator, balances []uint64, regenerateGenesis bool) ([]helpers.FeeEstimate, error) {
+	owner, err := plannedSubnetOwner()
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := plannedGenesis(regenerateGenesis)
+	if err != nil {
+		return nil, err
+	}
+
+	createSubnet, err := estimator.CreateSubnet(owner)
+	if err != nil {
+		return nil, err
+	}
+	createChain, err := estimator.CreateChain(owner, genesis, avagoconstants.SubnetEVMID, "My L1")
+	if err != nil {
+		return nil, err
+	}
+	convert, err := estimator.ConvertSubnetToL1(owner, make([]byte, 20), balances)
+	if err != nil {
+		return nil, err
+	}
+	return []helpers.FeeEstimate{createSubnet, createChain, convert}, nil
+}
+
+// plannedSubnetOwner is the owner of the subnet of the workspace, or the one
+// create-subnet would give it
+func plannedSubnetOwner() (*secp256k1fx.OutputOwners, error) {
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if manifest.SubnetID != ids.Empty {
+		owners, threshold, err := subnetOwners(manifest.SubnetID)
+		if err != nil {
+			return nil, err
+		}
+		return &secp256k1fx.OutputOwners{Threshold: threshold, Addrs: owners}, nil
+	}
+	owners, err := subnetOwnerAddresses()
+	if err != nil {
+		return nil, err
+	}
+	threshold := min(subnetThreshold, uint32(len(owners)))
+	return &secp256k1fx.OutputOwners{Threshold: max(threshold, 1), Addrs: owners}, nil
+}
+
+// plannedGenesis is the genesis of the workspace. If it wasn't generated yet,
+// or regenerate is set, it is the one generate-genesis would write with the
+// current flags and spec, built without saving it.
+func plannedGenesis(regenerate bool) ([]byte, error) {
+	exists, err := helpers.FileExists(helpers.L1GenesisPath)
+	if err != nil {
+		return nil, err
+	}
+	if exists && !regenerate {
+		genesis, err := helpers.LoadText(helpers.L1GenesisPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load genesis: %w", err)
+		}
+		return []byte(genesis), nil
+	}
+	spec, err := loadGenesisSpec()
+	if err != nil {
+		return nil, err
+	}
+	return buildL1Genesis(spec)
+}
+
+// activeValidatorCount is how many validators sign warp messages of the L1
+func activeValidatorCount() (int, error) {
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	count := 0
+	for _, validator := range manifest.Validators {
+		if !validator.Removed {
+			count++
+		}
+	}
+	return max(count, 1), nil
+}
+
+func printFeeEstimates(estimates []helpers.FeeEstimate, balance uint64) uint64 {
+	total := uint64(0)
+	for _, estimate := range estimates {
+		if estimate.Balance > 0 {
+			log.Printf("  %-40s %s AVAX fee + %s AVAX validator balance\n", estimate.Name, formatAVAX(estimate.Fee), formatAVAX(estimate.Balance))
+		} else {
+			log.Printf("  %-40s %s AVAX fee\n", estimate.Name, formatAVAX(estimate.Fee))
+		}
+		total += estimate.Total()
+	}
+	status := "✅"
+	if total > balance {
+		status = "❌"
+	}
+	log.Printf("  %s needs %s AVAX, the fee payer has %s AVAX on the P-chain\n", status, formatAVAX(total), formatAVAX(balance))
+	return total
+}
+
+// requirePChainFunds refuses to go on if the fee payer can't pay for every
+// tx of estimates, plus incoming funds that will reach the P-chain first
+func requirePChainFunds(estimates []helpers.FeeEstimate, balance uint64, incoming uint64) error {
+	printFeeEstimates(estimates, balance)
+	missing := pChainShortfall(estimates, balance, incoming)
+	if missing == 0 {
+		return nil
+	}
+	feePayer, err := roleAddressString(helpers.RoleFeePayer)
+	if err != nil {
+		return err
+	}
+	if currentNetwork.Name == config.FujiNetwork {
+		log.Printf("Get test AVAX from https://test.core.app/tools/testnet-faucet/\n")
+	}
+	return fmt.Errorf("the fee payer %s is %s AVAX short on the P-chain, move funds with `transfer --from c --to p --amount <AVAX>`", feePayer, formatAVAX(missing))
+}
+
+// pChainShortfall is what a fee payer with balance lacks to pay for every tx
+// of estimates, once incoming funds reached the P-chain
+func pChainShortfall(estimates []helpers.FeeEstimate, balance uint64, incoming uint64) uint64 {
+	total := uint64(0)
+	for _, estimate := range estimates {
+		total += estimate.Total()
+	}
+	if total <= balance+incoming {
+		return 0
+	}
+	return total - balance - incoming
+}
+
+// checkAddValidatorFunds is the pre-flight check of add-poa-validator giving
+// the new validator newBalance
+func checkAddValidatorFunds(newBalance uint64) error {
+	estimator, pBalance, err := pChainFeeEstimator()
+	if err != nil {
+		return err
+	}
+	signers, err := activeValidatorCount()
+	if err != nil {
+		return err
+	}
+	return requireAddValidatorFunds(estimator, pBalance, newBalance, signers)
+}
+
+// requireAddValidatorFunds checks that a fee payer with pBalance on the
+// P-chain can register a validator signed by signers and give it newBalance
+func requireAddValidatorFunds(estimator *helpers.FeeEstimator, pBalance uint64, newBalance uint64, signers int) error {
+	register, err := estimator.RegisterL1Validator(newBalance, signers)
+	if err != nil {
+		return err
+	}
+	return requirePChainFunds([]helpers.FeeEstimate{register}, pBalance, 0)
+}
+
+// checkRemoveValidatorFunds is the pre-flight check of remove-poa-validator
+func checkRemoveValidatorFunds() error {
+	estimator, balance, err := pChainFeeEstimator()
+	if err != nil {
+		return err
+	}
+	signers, err := activeValidatorCount()
+	if err != nil {
+		return err
+	}
+	setWeight, err := estimator.SetL1ValidatorWeight(signers)
+	if err != nil {
+		return err
+	}
+	return requirePChainFunds([]helpers.FeeEstimate{setWeight}, balance, 0)
+}
+
+// checkIncreaseBalanceFunds is the pre-flight check of increase-balance
+func checkIncreaseBalanceFunds(amount uint64) error {
+	estimator, balance, err := pChainFeeEstimator()
+	if err != nil {
+		return err
+	}
+	increase, err := estimator.IncreaseL1ValidatorBalance(amount)
+	if err != nil {
+		return err
+	}
+	return requirePChainFunds([]helpers.FeeEstimate{increase}, balance, 0)
+}
+
+// checkDisableValidatorFunds is the pre-flight check of disable-validator
+// for a validator deactivated by owner
+func checkDisableValidatorFunds(owner *secp256k1fx.OutputOwners) error {
+	estimator, balance, err := pChainFeeEstimator()
+	if err != nil {
+		return err
+	}
+	disable, err := estimator.DisableL1Validator(owner)
+	if err != nil {
+		return err
+	}
+	return requirePChainFunds([]helpers.FeeEstimate{disable}, balance, 0)
+}
+
+// checkUpFunds refuses to start up if the fee payer can't pay for the P-chain
+// steps between first and last that are still to do. Funds transfer-coins
+// would move from the C-chain count as available.
+func checkUpFunds(first, last int) error {
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	inRange := map[string]bool{}
+	for _, step := range pipelineSteps[first : last+1] {
+		inRange[step.name] = true
+	}
+	if inRange["generate-keys"] && manifest.Step("generate-keys").Status != helpers.StepDone {
+		log.Println("Skipping the P-chain fee check until the keys exist")
+		return nil
+	}
+	// Each command skips what the manifest already records
+	todo := []bool{
+		inRange["create-subnet"] && manifest.SubnetID == ids.Empty,
+		inRange["create-chain"] && manifest.ChainID == ids.Empty,
+		inRange["convert-to-L1"] && manifest.ConversionTxID == ids.Empty,
+	}
+	if !slices.Contains(todo, true) {
+		return nil
+	}
+
+	PrintHeader("🧮 Checking P-chain funds")
+	estimator, balance, err := pChainFeeEstimator()
+	if err != nil {
+		return err
+	}
+	bootstrapBalance, err := validatorBalance(convertValidatorBalance)
+	if err != nil {
+		return err
+	}
+	specs, err := parseBootstrapValidators(convertBootstrapValidators, bootstrapBalance)
+	if err != nil {
+		return err
+	}
+	// generate-genesis reruns if it is in range, --from and --only force
+	// every step they cover
+	regenerateGenesis := inRange["generate-genesis"] && (manifest.Step("generate-genesis").Status != helpers.StepDone || upFrom != "" || upOnly != "")
+	setup, err := setupFeeEstimates(estimator, bootstrapBalances(specs), regenerateGenesis)
+	if err != nil {
+		return err
+	}
+	estimates := []helpers.FeeEstimate{}
+	for i, estimate := range setup {
+		if todo[i] {
+			estimates = append(estimates, estimate)
+		}
+	}
+
+	incoming := uint64(0)
+	if inRange["transfer-coins"] && balance < MIN_BALANCE {
+		incoming, err = transferCoinsIncoming()
+		if err != nil {
+			return err
+		}
+		if incoming > 0 {
+			log.Printf("transfer-coins will bring about %s AVAX from the C-chain\n", formatAVAX(incoming))
+		}
+	}
+	return requirePChainFunds(estimates, balance, incoming)
+}
+
+// transferCoinsIncoming is what transfer-coins exports from the C-chain: the
+// whole balance of the fee payer there minus what it keeps for fees
+func transferCoinsIncoming() (uint64, error) {
+	feePayer, err := roleSigner(helpers.RoleFeePayer)
+	if errors.Is(err, errRoleWithoutKey) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	client, err := ethclient.Dial(currentNetwork.CChainURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to c-chain: %w", err)
+	}
+	defer client.Close()
+	balance, err := client.BalanceAt(context.Background(), feePayer.EthAddress(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get C-chain balance: %w", err)
+	}
+	// C-chain balances are in wei, P-chain ones in nAVAX
+	nAVAX := balance.Div(balance, big.NewInt(int64(units.Avax))).Uint64()
+	if nAVAX <= 100*units.MilliAvax {
+		return 0, nil
+	}
+	return nAVAX - 100*units.MilliAvax, nil
+}
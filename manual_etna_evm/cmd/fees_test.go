@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/gas"
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testFeeEstimator() *helpers.FeeEstimator {
+	return helpers.NewFeeEstimator(&pbuilder.Context{
+		NetworkID:   5,
+		AVAXAssetID: ids.GenerateTestID(),
+		ComplexityWeights: gas.Dimensions{
+			gas.Bandwidth: 1,
+			gas.DBRead:    1,
+			gas.DBWrite:   1,
+			gas.Compute:   1,
+		},
+		GasPrice: 1,
+	}, ids.GenerateTestShortID())
+}
+
+func TestPChainShortfall(t *testing.T) {
+	estimates := []helpers.FeeEstimate{
+		{Name: "a", Fee: 10, Balance: 0},
+		{Name: "b", Fee: 5, Balance: 100},
+	}
+	tests := []struct {
+		name     string
+		balance  uint64
+		incoming uint64
+		want     uint64
+	}{
+		{"enough", 200, 0, 0},
+		{"exact", 115, 0, 0},
+		{"short", 100, 0, 15},
+		{"incoming covers it", 100, 15, 0},
+		{"incoming isn't enough", 50, 15, 50},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := pChainShortfall(estimates, test.balance, test.incoming); got != test.want {
+				t.Fatalf("pChainShortfall(%d, %d) = %d, want %d", test.balance, test.incoming, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRequireAddValidatorFunds(t *testing.T) {
+	estimator := testFeeEstimator()
+	tests := []struct {
+		name       string
+		pBalance   uint64
+		newBalance uint64
+	}{
+		{"default balance", 10 * units.Avax, units.Avax},
+		{"large balance", 10 * units.Avax, 9 * units.Avax},
+		{"no balance", units.Avax, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Enough funds never reach the fee payer lookup of the shortfall
+			// error, so this runs without a workspace
+			if err := requireAddValidatorFunds(estimator, test.pBalance, test.newBalance, 1); err != nil {
+				t.Fatalf("fee payer with %d can't give %d: %s", test.pBalance, test.newBalance, err)
+			}
+		})
+	}
+
+	register, err := estimator.RegisterL1Validator(units.Avax, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if register.Balance != units.Avax {
+		t.Fatalf("registration hands %d to the validator, want %d", register.Balance, units.Avax)
+	}
+	if missing := pChainShortfall([]helpers.FeeEstimate{register}, units.Avax, 0); missing != register.Fee {
+		t.Fatalf("shortfall of a fee payer holding just the validator balance is %d, want the fee %d", missing, register.Fee)
+	}
+}
+
+func TestPlannedGenesis(t *testing.T) {
+	inTempDir(t)
+	t.Cleanup(func() { managerInGenesis, validatorType = false, "" })
+	managerInGenesis, validatorType = true, config.PoAMode
+	key, err := secp256k1.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helpers.SaveSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath, key); err != nil {
+		t.Fatal(err)
+	}
+	extra := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	if err := helpers.SaveText(helpers.GenesisSpecPath, "alloc:\n  \""+extra.Hex()+"\":\n    balance: 42\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a genesis, it is the one generate-genesis would write
+	planned, err := plannedGenesis(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis := &core.Genesis{}
+	if err := json.Unmarshal(planned, genesis); err != nil {
+		t.Fatalf("failed to parse the planned genesis: %s", err)
+	}
+	for _, address := range []string{config.ValidatorManagerImplementationAddress, config.ValidatorMessagesAddress, extra.Hex()} {
+		if _, ok := genesis.Alloc[common.HexToAddress(address)]; !ok {
+			t.Fatalf("the planned genesis has no account %s", address)
+		}
+	}
+	if exists, err := helpers.FileExists(helpers.L1GenesisPath); err != nil || exists {
+		t.Fatalf("the planned genesis was saved (%v)", err)
+	}
+
+	// A generated genesis is used as is, unless it is about to be regenerated
+	if err := helpers.SaveText(helpers.L1GenesisPath, "{}"); err != nil {
+		t.Fatal(err)
+	}
+	if planned, err := plannedGenesis(false); err != nil || string(planned) != "{}" {
+		t.Fatalf("got %q (%v), want the genesis of the workspace", planned, err)
+	}
+	if planned, err := plannedGenesis(true); err != nil || string(planned) == "{}" {
+		t.Fatalf("got %q (%v), want a new genesis", planned, err)
+	}
+}
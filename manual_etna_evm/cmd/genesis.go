@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	genesisCmd.AddCommand(genesisInspectCmd, genesisDiffCmd)
+	rootCmd.AddCommand(genesisCmd)
+}
+
+var genesisCmd = &cobra.Command{
+	Use:         "genesis",
+	Annotations: offline,
+	Short:       "Inspect and compare subnet-evm genesis files",
+}
+
+var genesisInspectCmd = &cobra.Command{
+	Use:         "inspect [genesis.json]",
+	Annotations: offline,
+	Short:       "Print a subnet-evm genesis in readable form, the L1 genesis of the workspace by default",
+	Long: `Print a subnet-evm genesis in readable form, the L1 genesis of the workspace by default.
+
+Shows the chain config, fee config, warp config, precompiles and every
+allocation. Known contracts are named by their address or bytecode, and the
+storage slots of the proxies are decoded into addresses: the owner of the
+ProxyAdmin and the EIP-1967 implementation and admin of the proxy.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := helpers.L1GenesisPath
+		if len(args) == 1 {
+			path = args[0]
+		}
+		fields, err := describeGenesisFile(path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Genesis %s\n", path)
+		section := ""
+		for _, field := range fields {
+			if field.section != section {
+				section = field.section
+				fmt.Printf("\n%s:\n", section)
+			}
+			fmt.Printf("  %s: %s\n", field.key, field.value)
+		}
+		return nil
+	},
+}
+
+var genesisDiffCmd = &cobra.Command{
+	Use:         "diff <a> <b>",
+	Annotations: offline,
+	Short:       "Show what changes from one subnet-evm genesis to another, field by field",
+	Long: `Show what changes from one subnet-evm genesis to another, field by field.
+
+Both files are decoded like genesis inspect does, so storage shows up as the
+addresses it holds rather than as hex. Lines starting with - are only in <a>,
++ only in <b> and ~ changed from <a> to <b>.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		before, err := describeGenesisFile(args[0])
+		if err != nil {
+			return err
+		}
+		after, err := describeGenesisFile(args[1])
+		if err != nil {
+			return err
+		}
+
+		changes := diffGenesisFields(before, after)
+		if len(changes) == 0 {
+			fmt.Printf("%s and %s are the same genesis\n", args[0], args[1])
+			return nil
+		}
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+		return nil
+	},
+}
+
+// genesisField is one readable line of a genesis, key is unique within section
+type genesisField struct {
+	section string
+	key     string
+	value   string
+}
+
+func (f genesisField) id() string {
+	return f.section + " " + f.key
+}
+
+func describeGenesisFile(path string) ([]genesisField, error) {
+	genesisBytes, err := helpers.LoadBytes(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genesis: %w", err)
+	}
+	genesis := &core.Genesis{}
+	if err := json.Unmarshal(genesisBytes, genesis); err != nil {
+		return nil, fmt.Errorf("failed to parse genesis %s: %w", path, err)
+	}
+	if genesis.Config == nil {
+		return nil, fmt.Errorf("genesis %s has no chain config", path)
+	}
+	return describeGenesis(genesis)
+}
+
+// describeGenesis lists the fields of genesis in the order they are printed
+func describeGenesis(genesis *core.Genesis) ([]genesisField, error) {
+	fields := []genesisField{
+		{"genesis", "timestamp", fmt.Sprintf("%d (%s)", genesis.Timestamp, time.Unix(int64(genesis.Timestamp), 0).UTC().Format(time.RFC3339))},
+		{"genesis", "gasLimit", fmt.Sprint(genesis.GasLimit)},
+	}
+	if genesis.Difficulty != nil && genesis.Difficulty.Sign() != 0 {
+		fields = append(fields, genesisField{"genesis", "difficulty", genesis.Difficulty.String()})
+	}
+	if genesis.BaseFee != nil {
+		fields = append(fields, genesisField{"genesis", "baseFeePerGas", genesis.BaseFee.String()})
+	}
+	if len(genesis.ExtraData) > 0 {
+		fields = append(fields, genesisField{"genesis", "extraData", fmt.Sprintf("0x%x", genesis.ExtraData)})
+	}
+	if genesis.Coinbase != (common.Address{}) {
+		fields = append(fields, genesisField{"genesis", "coinbase", genesis.Coinbase.Hex()})
+	}
+
+	// The chain config is described from its JSON, which has the
+	// precompiles inline next to the regular fields
+	configJSON, err := json.Marshal(genesis.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal chain config: %w", err)
+	}
+	chainConfig, err := decodeJSONObject(configJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode chain config: %w", err)
+	}
+	chainFields, feeFields, warpFields, precompileFields := []genesisField{}, []genesisField{}, []genesisField{}, []genesisField{}
+	for _, key := range sortedKeys(chainConfig) {
+		value := chainConfig[key]
+		_, isPrecompile := genesis.Config.GenesisPrecompiles[key]
+		switch {
+		case key == "feeConfig":
+			feeFields = objectFields("fee config", "", value)
+		case key == warp.ConfigKey:
+			warpFields = objectFields("warp", "", value)
+		case isPrecompile:
+			precompileFields = append(precompileFields, objectFields("precompiles", key+".", value)...)
+		default:
+			chainFields = append(chainFields, genesisField{"chain config", key, string(value)})
+		}
+	}
+	fields = append(fields, chainFields...)
+	fields = append(fields, feeFields...)
+	fields = append(fields, warpFields...)
+	fields = append(fields, precompileFields...)
+
+	knownCode, err := knownGenesisCode()
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]common.Address, 0, len(genesis.Alloc))
+	for addr := range genesis.Alloc {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	for _, addr := range addrs {
+		fields = append(fields, allocFields(addr, genesis.Alloc[addr], knownCode)...)
+	}
+	return fields, nil
+}
+
+// allocFields describes one account, naming its code and decoding the
+// storage slots of the contracts that hold addresses
+func allocFields(addr common.Address, account types.Account, knownCode map[common.Hash]string) []genesisField {
+	section := "alloc " + labelAddress(addr)
+	fields := []genesisField{}
+	if account.Balance != nil {
+		fields = append(fields, genesisField{section, "balance", fmt.Sprintf("%s (%s wei)", GetBalanceString(account.Balance, 18), account.Balance)})
+	}
+	if account.Nonce != 0 {
+		fields = append(fields, genesisField{section, "nonce", fmt.Sprint(account.Nonce)})
+	}
+
+	codeName := ""
+	if len(account.Code) > 0 {
+		codeHash := crypto.Keccak256Hash(account.Code)
+		codeName = knownCode[codeHash]
+		name := codeName
+		if name == "" {
+			name = "unknown code"
+		}
+		fields = append(fields, genesisField{section, "code", fmt.Sprintf("%s, %d bytes, keccak256 %s", name, len(account.Code), codeHash)})
+	}
+
+	slots := make([]common.Hash, 0, len(account.Storage))
+	for slot := range account.Storage {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return bytes.Compare(slots[i][:], slots[j][:]) < 0 })
+	for _, slot := range slots {
+		value := account.Storage[slot]
+		slotName := ""
+		switch {
+		case slot == eip1967ImplementationSlot:
+			slotName = "implementation"
+		case slot == eip1967AdminSlot:
+			slotName = "admin"
+		case slot == (common.Hash{}) && strings.HasPrefix(codeName, "ProxyAdmin"):
+			slotName = "owner"
+		}
+		if slotName == "" {
+			fields = append(fields, genesisField{section, "storage " + slot.Hex(), value.Hex()})
+			continue
+		}
+		fields = append(fields, genesisField{section, slotName, labelAddress(common.BytesToAddress(value.Bytes()))})
+	}
+	return fields
+}
+
+// knownGenesisAddresses names the accounts generate-genesis allocates
+var knownGenesisAddresses = map[common.Address]string{
+	common.HexToAddress(config.ProxyAdminContractAddress):             "ProxyAdmin",
+	common.HexToAddress(config.ProxyContractAddress):                  "validator manager proxy",
+	common.HexToAddress(config.ValidatorManagerImplementationAddress): "validator manager implementation",
+	common.HexToAddress(config.ValidatorMessagesAddress):              "ValidatorMessages",
+	common.HexToAddress(config.RewardCalculatorAddress):               "reward calculator",
+}
+
+func labelAddress(addr common.Address) string {
+	if name, ok := knownGenesisAddresses[addr]; ok {
+		return fmt.Sprintf("%s (%s)", addr.Hex(), name)
+	}
+	return addr.Hex()
+}
+
+// knownGenesisCode maps the code hash of each contract generate-genesis can
+// embed to its name
+func knownGenesisCode() (map[common.Hash]string, error) {
+	knownCode := map[common.Hash]string{}
+	for _, name := range proxyFlavorNames() {
+		flavor := proxyFlavors[name]
+		knownCode[flavor.proxyCodeHash] = fmt.Sprintf("TransparentUpgradeableProxy (%s)", name)
+		knownCode[flavor.adminCodeHash] = fmt.Sprintf("ProxyAdmin (%s)", name)
+	}
+	names := map[string]string{
+		config.ValidatorManagerImplementationAddress: "%s validator manager",
+		config.ValidatorMessagesAddress:              "ValidatorMessages",
+		config.RewardCalculatorAddress:               "ExampleRewardCalculator",
+	}
+	for _, validatorType := range []string{config.PoAMode, config.PoSNativeMode} {
+		alloc, err := genesisValidatorManagerAlloc(validatorType)
+		if err != nil {
+			return nil, err
+		}
+		for addr, name := range names {
+			if account, ok := alloc[common.HexToAddress(addr)]; ok {
+				knownCode[crypto.Keccak256Hash(account.Code)] = strings.ReplaceAll(name, "%s", validatorType)
+			}
+		}
+	}
+	return knownCode, nil
+}
+
+// objectFields lists the fields of a JSON object, prefixing their keys
+func objectFields(section string, prefix string, value json.RawMessage) []genesisField {
+	object, err := decodeJSONObject(value)
+	if err != nil {
+		return []genesisField{{section, strings.TrimSuffix(prefix, "."), string(value)}}
+	}
+	fields := []genesisField{}
+	for _, key := range sortedKeys(object) {
+		fields = append(fields, genesisField{section, prefix + key, string(object[key])})
+	}
+	return fields
+}
+
+func decodeJSONObject(data []byte) (map[string]json.RawMessage, error) {
+	object := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func sortedKeys(object map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffGenesisFields returns a line per field removed, added or changed from
+// before to after, in the order of after with the removed fields first
+func diffGenesisFields(before []genesisField, after []genesisField) []string {
+	afterByID := map[string]genesisField{}
+	for _, field := range after {
+		afterByID[field.id()] = field
+	}
+	beforeByID := map[string]genesisField{}
+	changes := []string{}
+	for _, field := range before {
+		beforeByID[field.id()] = field
+		if _, ok := afterByID[field.id()]; !ok {
+			changes = append(changes, fmt.Sprintf("- %s %s: %s", field.section, field.key, field.value))
+		}
+	}
+	for _, field := range after {
+		old, ok := beforeByID[field.id()]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("+ %s %s: %s", field.section, field.key, field.value))
+		case old.value != field.value:
+			changes = append(changes, fmt.Sprintf("~ %s %s: %s -> %s", field.section, field.key, old.value, field.value))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/deployerallowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/feemanager"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/nativeminter"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/rewardmanager"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ava-labs/subnet-evm/precompile/precompileconfig"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// genesisPrecompileConfigs are the precompiles a genesis spec or the
+// --precompile-* flags can enable, by the name used in both
+var genesisPrecompileConfigs = map[string]func() precompileconfig.Config{
+	"txAllowList":               func() precompileconfig.Config { return &txallowlist.Config{} },
+	"contractDeployerAllowList": func() precompileconfig.Config { return &deployerallowlist.Config{} },
+	"contractNativeMinter":      func() precompileconfig.Config { return &nativeminter.Config{} },
+	"feeManager":                func() precompileconfig.Config { return &feemanager.Config{} },
+	"rewardManager":             func() precompileconfig.Config { return &rewardmanager.Config{} },
+}
+
+var (
+	precompileAdmins   []string
+	precompileManagers []string
+	precompileEnabled  []string
+)
+
+func genesisPrecompileNames() []string {
+	names := []string{}
+	for name := range genesisPrecompileConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// genesisPrecompiles builds the precompiles of spec and of the --precompile-*
+// flags, all activated at timestamp unless the spec says otherwise
+func genesisPrecompiles(spec *helpers.GenesisSpec, timestamp uint64) (params.Precompiles, error) {
+	precompiles := params.Precompiles{}
+	for name, raw := range spec.Precompiles {
+		config, err := parseGenesisPrecompile(name, raw, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		precompiles[config.Key()] = config
+	}
+
+	flagRoles := []struct {
+		flag   string
+		values []string
+		list   func(config *allowlist.AllowListConfig) *[]common.Address
+	}{
+		{"--precompile-admin", precompileAdmins, func(c *allowlist.AllowListConfig) *[]common.Address { return &c.AdminAddresses }},
+		{"--precompile-manager", precompileManagers, func(c *allowlist.AllowListConfig) *[]common.Address { return &c.ManagerAddresses }},
+		{"--precompile-enabled", precompileEnabled, func(c *allowlist.AllowListConfig) *[]common.Address { return &c.EnabledAddresses }},
+	}
+	for _, role := range flagRoles {
+		for _, value := range role.values {
+			name, addrs, err := parsePrecompileAddressFlag(role.flag, value)
+			if err != nil {
+				return nil, err
+			}
+			newConfig := genesisPrecompileConfigs[name]
+			key := newConfig().Key()
+			if _, ok := precompiles[key]; !ok {
+				precompiles[key], err = parseGenesisPrecompile(name, nil, timestamp)
+				if err != nil {
+					return nil, err
+				}
+			}
+			list := role.list(precompileAllowList(precompiles[key]))
+			for _, addr := range addrs {
+				if !slices.Contains(*list, addr) {
+					*list = append(*list, addr)
+				}
+			}
+		}
+	}
+	return precompiles, nil
+}
+
+// parseGenesisPrecompile decodes raw, in the subnet-evm format of the
+// precompile config, and activates it at timestamp if it doesn't say when
+func parseGenesisPrecompile(name string, raw json.RawMessage, timestamp uint64) (precompileconfig.Config, error) {
+	newConfig, ok := genesisPrecompileConfigs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown precompile %q, must be one of: %s", name, strings.Join(genesisPrecompileNames(), ", "))
+	}
+	fields := map[string]json.RawMessage{}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("invalid %s precompile config: %w", name, err)
+		}
+	}
+	if _, ok := fields["blockTimestamp"]; !ok {
+		fields["blockTimestamp"] = json.RawMessage(fmt.Sprint(timestamp))
+	}
+	withTimestamp, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s precompile config: %w", name, err)
+	}
+
+	config := newConfig()
+	if err := helpers.DecodeStrictJSON(withTimestamp, config); err != nil {
+		return nil, fmt.Errorf("invalid %s precompile config: %w", name, err)
+	}
+	return config, nil
+}
+
+// parsePrecompileAddressFlag parses name=0xaddr[,0xaddr...]
+func parsePrecompileAddressFlag(flag string, value string) (string, []common.Address, error) {
+	name, list, ok := strings.Cut(value, "=")
+	if !ok {
+		return "", nil, fmt.Errorf("%s %q must look like <precompile>=<address>[,<address>...]", flag, value)
+	}
+	if _, ok := genesisPrecompileConfigs[name]; !ok {
+		return "", nil, fmt.Errorf("%s: unknown precompile %q, must be one of: %s", flag, name, strings.Join(genesisPrecompileNames(), ", "))
+	}
+	addrs := []common.Address{}
+	for _, addr := range strings.Split(list, ",") {
+		if !common.IsHexAddress(addr) {
+			return "", nil, fmt.Errorf("%s: %q is not a 0x hex address", flag, addr)
+		}
+		addrs = append(addrs, common.HexToAddress(addr))
+	}
+	return name, addrs, nil
+}
+
+func precompileAllowList(config precompileconfig.Config) *allowlist.AllowListConfig {
+	switch config := config.(type) {
+	case *txallowlist.Config:
+		return &config.AllowListConfig
+	case *deployerallowlist.Config:
+		return &config.AllowListConfig
+	case *nativeminter.Config:
+		return &config.AllowListConfig
+	case *feemanager.Config:
+		return &config.AllowListConfig
+	case *rewardmanager.Config:
+		return &config.AllowListConfig
+	default:
+		return nil
+	}
+}
+
+// allowSetupAccounts enables the accounts the setup steps send L1 txs from in
+// the allow lists that would otherwise lock them out
+func allowSetupAccounts(precompiles params.Precompiles, deployer common.Address, managerOwner common.Address) {
+	required := map[string][]common.Address{
+		txallowlist.ConfigKey:       {deployer, managerOwner},
+		deployerallowlist.ConfigKey: {deployer},
+	}
+	for key, addrs := range required {
+		config, ok := precompiles[key]
+		if !ok {
+			continue
+		}
+		list := precompileAllowList(config)
+		for _, addr := range addrs {
+			if slices.Contains(list.AdminAddresses, addr) || slices.Contains(list.ManagerAddresses, addr) || slices.Contains(list.EnabledAddresses, addr) {
+				continue
+			}
+			list.EnabledAddresses = append(list.EnabledAddresses, addr)
+			log.Printf("Enabled %s in %s, the setup steps send transactions from it\n", addr, key)
+		}
+	}
+}
+
+// verifyGenesisPrecompiles runs the checks of the subnet-evm precompile
+// modules, on a new L1 where every network upgrade is active from genesis
+func verifyGenesisPrecompiles(chainConfig *params.ChainConfig) error {
+	verifyConfig := *chainConfig
+	verifyConfig.NetworkUpgrades = params.NetworkUpgrades{
+		SubnetEVMTimestamp: utils.NewUint64(0),
+		DurangoTimestamp:   utils.NewUint64(0),
+		EtnaTimestamp:      utils.NewUint64(0),
+	}
+	for key, config := range chainConfig.GenesisPrecompiles {
+		if err := config.Verify(&verifyConfig); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	transparentupgradeableproxy "github.com/ava-labs/icm-contracts/abi-bindings/go/TransparentUpgradeableProxy"
+)
+
+//go:embed proxy_compiled/deployed_proxy_admin_bytecode.txt
+var proxyAdminBytecodeHexString string
+
+//go:embed proxy_compiled/deployed_transparent_proxy_bytecode.txt
+var transparentProxyBytecodeHexString string
+
+// proxyFlavor is a proxy generate-genesis can put in front of the validator
+// manager, together with the admin contract that upgrades it
+type proxyFlavor struct {
+	// source is the contracts release the bytecode was compiled from
+	source string
+	// proxyCodeHash and adminCodeHash are the keccak256 of the runtime code
+	// of the proxy and of its admin, checked before a genesis is written
+	proxyCodeHash common.Hash
+	adminCodeHash common.Hash
+	// alloc returns the proxy at config.ProxyContractAddress and its admin at
+	// config.ProxyAdminContractAddress
+	alloc func(implementation common.Address, proxyAdminOwner common.Address) (types.GenesisAlloc, error)
+}
+
+var proxyFlavors = map[string]proxyFlavor{
+	config.ProxyOZ4Transparent: {
+		source:        "OpenZeppelin Contracts 4.9, cmd/proxy_compiled",
+		proxyCodeHash: common.HexToHash("0x4a2b067ac5785834393da4d5b84fcd19df52b9739917fdcd3fd30bcd970d9025"),
+		adminCodeHash: common.HexToHash("0xf9a587abdb0454f360be1b2fdb051822560d85328bfcc93d41275f13d9271f04"),
+		alloc:         oz4TransparentProxyAlloc,
+	},
+	config.ProxyOZ5Transparent: {
+		source:        "OpenZeppelin Contracts 5.0.2, icm-contracts TransparentUpgradeableProxy bindings",
+		proxyCodeHash: common.HexToHash("0x93239e0692d9cc37588fe445b0fda426239ea0715be299002e3f175231acc058"),
+		adminCodeHash: common.HexToHash("0x763f7a7aedd5e7ddc04ba90e97f1c447926049e1b55f7f294cfe77239372e749"),
+		alloc:         oz5TransparentProxyAlloc,
+	},
+}
+
+func proxyFlavorNames() []string {
+	names := []string{}
+	for name := range proxyFlavors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// genesisProxyAlloc returns the accounts of the flavor proxy pointing at
+// implementation, after checking their code is the recorded one
+func genesisProxyAlloc(flavorName string, implementation common.Address, proxyAdminOwner common.Address) (types.GenesisAlloc, error) {
+	if flavorName == config.ProxyUUPS {
+		return nil, fmt.Errorf("UUPS proxies aren't supported: a UUPS proxy is upgraded by its implementation, and the built-in validator managers aren't UUPSUpgradeable; use %s", strings.Join(proxyFlavorNames(), " or "))
+	}
+	flavor, ok := proxyFlavors[flavorName]
+	if !ok {
+		return nil, fmt.Errorf("unknown proxy %q, must be one of: %s", flavorName, strings.Join(proxyFlavorNames(), ", "))
+	}
+	alloc, err := flavor.alloc(implementation, proxyAdminOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s proxy: %w", flavorName, err)
+	}
+
+	expected := map[string]common.Hash{
+		config.ProxyContractAddress:      flavor.proxyCodeHash,
+		config.ProxyAdminContractAddress: flavor.adminCodeHash,
+	}
+	for addr, codeHash := range expected {
+		actual := crypto.Keccak256Hash(alloc[common.HexToAddress(addr)].Code)
+		if actual != codeHash {
+			return nil, fmt.Errorf("%s bytecode at %s has keccak256 %s, expected %s from %s", flavorName, addr, actual, codeHash, flavor.source)
+		}
+	}
+	return alloc, nil
+}
+
+// oz4TransparentProxyAlloc places the precompiled proxy and ProxyAdmin, and
+// sets the slots their constructors would have
+func oz4TransparentProxyAlloc(implementation common.Address, proxyAdminOwner common.Address) (types.GenesisAlloc, error) {
+	proxyAdminBytecode, transparentProxyBytecode, err := proxyBytecodes()
+	if err != nil {
+		return nil, err
+	}
+	return types.GenesisAlloc{
+		common.HexToAddress(config.ProxyAdminContractAddress): {
+			Balance: big.NewInt(0),
+			Code:    proxyAdminBytecode,
+			Nonce:   1,
+			Storage: map[common.Hash]common.Hash{
+				common.HexToHash("0x0"): common.HexToHash(proxyAdminOwner.String()),
+			},
+		},
+		common.HexToAddress(config.ProxyContractAddress): {
+			Balance: big.NewInt(0),
+			Code:    transparentProxyBytecode,
+			Nonce:   1,
+			Storage: map[common.Hash]common.Hash{
+				eip1967ImplementationSlot: common.HexToHash(implementation.String()),
+				eip1967AdminSlot:          common.HexToHash(config.ProxyAdminContractAddress),
+			},
+		},
+	}, nil
+}
+
+// oz5TransparentProxyAlloc runs the constructor of the OpenZeppelin 5 proxy,
+// which deploys a ProxyAdmin of its own and keeps its address as an
+// immutable. That address is moved to config.ProxyAdminContractAddress, in
+// the code of the proxy and in its admin slot.
+func oz5TransparentProxyAlloc(implementation common.Address, proxyAdminOwner common.Address) (types.GenesisAlloc, error) {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create in-memory state: %w", err)
+	}
+	// The constructor requires code at the implementation, which is only
+	// deployed after launch unless the manager is in the genesis
+	statedb.SetCode(implementation, []byte{0})
+
+	parsed, err := transparentupgradeableproxy.TransparentUpgradeableProxyMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	args, err := parsed.Pack("", implementation, proxyAdminOwner, []byte{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack constructor arguments: %w", err)
+	}
+	proxyCode, created, _, err := runtime.Create(append(common.FromHex(transparentupgradeableproxy.TransparentUpgradeableProxyMetaData.Bin), args...), &runtime.Config{
+		ChainConfig: genesisEVMChainConfig(),
+		State:       statedb,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("constructor failed: %w", err)
+	}
+
+	createdAdmin := crypto.CreateAddress(created, 1)
+	if common.BytesToAddress(statedb.GetState(created, eip1967AdminSlot).Bytes()) != createdAdmin {
+		return nil, fmt.Errorf("the proxy admin isn't the contract the constructor deployed")
+	}
+	if common.BytesToAddress(statedb.GetState(created, eip1967ImplementationSlot).Bytes()) != implementation {
+		return nil, fmt.Errorf("the proxy doesn't point at %s", implementation)
+	}
+	if common.BytesToAddress(statedb.GetState(createdAdmin, common.Hash{}).Bytes()) != proxyAdminOwner {
+		return nil, fmt.Errorf("the proxy admin isn't owned by %s", proxyAdminOwner)
+	}
+	if !bytes.Contains(proxyCode, createdAdmin.Bytes()) {
+		return nil, fmt.Errorf("the proxy code doesn't hold its admin address")
+	}
+
+	proxyAdmin := common.HexToAddress(config.ProxyAdminContractAddress)
+	return types.GenesisAlloc{
+		proxyAdmin: {
+			Balance: big.NewInt(0),
+			Code:    statedb.GetCode(createdAdmin),
+			Nonce:   1,
+			Storage: map[common.Hash]common.Hash{
+				{}: common.BytesToHash(proxyAdminOwner.Bytes()),
+			},
+		},
+		common.HexToAddress(config.ProxyContractAddress): {
+			Balance: big.NewInt(0),
+			Code:    bytes.ReplaceAll(proxyCode, createdAdmin.Bytes(), proxyAdmin.Bytes()),
+			Nonce:   1,
+			Storage: map[common.Hash]common.Hash{
+				eip1967ImplementationSlot: common.BytesToHash(implementation.Bytes()),
+				eip1967AdminSlot:          common.BytesToHash(proxyAdmin.Bytes()),
+			},
+		},
+	}, nil
+}
+
+// proxyBytecodes decodes the embedded runtime bytecode of the proxy admin and
+// of the transparent proxy
+func proxyBytecodes() ([]byte, []byte, error) {
+	proxyAdminBytecode, err := hex.DecodeString(strings.TrimSpace(strings.TrimPrefix(proxyAdminBytecodeHexString, "0x")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode proxy admin bytecode: %w", err)
+	}
+
+	transparentProxyBytecode, err := hex.DecodeString(strings.TrimSpace(strings.TrimPrefix(transparentProxyBytecodeHexString, "0x")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode transparent proxy bytecode: %w", err)
+	}
+	return proxyAdminBytecode, transparentProxyBytecode, nil
+}
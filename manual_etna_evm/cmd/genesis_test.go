@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDiffGenesisFields(t *testing.T) {
+	before := []genesisField{
+		{"genesis", "timestamp", "1"},
+		{"genesis", "gasLimit", "8000000"},
+		{"alloc 0xaa", "balance", "1"},
+		{"alloc 0xbb", "balance", "2"},
+	}
+	after := []genesisField{
+		{"genesis", "timestamp", "1"},
+		{"genesis", "gasLimit", "12000000"},
+		{"alloc 0xbb", "balance", "2"},
+		{"alloc 0xcc", "balance", "3"},
+	}
+	want := []string{
+		"- alloc 0xaa balance: 1",
+		"~ genesis gasLimit: 8000000 -> 12000000",
+		"+ alloc 0xcc balance: 3",
+	}
+	if got := diffGenesisFields(before, after); !reflect.DeepEqual(got, want) {
+		t.Fatalf("diff is\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := diffGenesisFields(after, after); len(got) != 0 {
+		t.Fatalf("a genesis differs from itself: %v", got)
+	}
+	// The same key in two sections is two fields
+	if got := diffGenesisFields([]genesisField{{"a", "balance", "1"}}, []genesisField{{"b", "balance", "1"}}); len(got) != 2 {
+		t.Fatalf("moving a field between sections gives %v, want a removal and an addition", got)
+	}
+}
+
+func TestDiffDescribedGenesis(t *testing.T) {
+	addr := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	genesis := func(balance int64, gasLimit uint64) *core.Genesis {
+		return &core.Genesis{
+			Config:     &params.ChainConfig{ChainID: big.NewInt(1)},
+			Alloc:      types.GenesisAlloc{addr: {Balance: big.NewInt(balance)}},
+			Difficulty: big.NewInt(0),
+			GasLimit:   gasLimit,
+		}
+	}
+	before, err := describeGenesis(genesis(1, 8000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := describeGenesis(genesis(2, 8000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := diffGenesisFields(before, after)
+	if len(changes) != 1 || !strings.HasPrefix(changes[0], "~ ") || !strings.Contains(changes[0], "balance") {
+		t.Fatalf("changing one balance gives %v, want one balance change", changes)
+	}
+}
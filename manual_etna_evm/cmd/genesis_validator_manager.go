@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm/runtime"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+
+	examplerewardcalculator "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/ExampleRewardCalculator"
+	nativetokenstakingmanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/NativeTokenStakingManager"
+	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
+)
+
+// validatorMessagesPlaceholder is where the validator manager bytecode links
+// the ValidatorMessages library
+const validatorMessagesPlaceholder = "__$fd0c147b4031eef6079b0498cbafa865f0$__"
+
+var (
+	// eip1967ImplementationSlot holds the implementation of the transparent
+	// proxy, bytes32(uint256(keccak256('eip1967.proxy.implementation')) - 1)
+	eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	// eip1967AdminSlot holds its admin,
+	// bytes32(uint256(keccak256('eip1967.proxy.admin')) - 1)
+	eip1967AdminSlot = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+)
+
+// genesisValidatorManagerAlloc returns the genesis accounts of a validatorType
+// manager implementation at its fixed address, with ValidatorMessages linked
+// in and, for PoS, the example reward calculator
+func genesisValidatorManagerAlloc(validatorType string) (types.GenesisAlloc, error) {
+	var managerMetaData *bind.MetaData
+	switch validatorType {
+	case config.PoAMode:
+		managerMetaData = poavalidatormanager.PoAValidatorManagerMetaData
+	case config.PoSNativeMode:
+		managerMetaData = nativetokenstakingmanager.NativeTokenStakingManagerMetaData
+	default:
+		return nil, fmt.Errorf("invalid validator type: %s. Must be either '%s' or '%s'", validatorType, config.PoAMode, config.PoSNativeMode)
+	}
+
+	messagesAddress := common.HexToAddress(config.ValidatorMessagesAddress)
+	messagesCode, err := deployedBytecode(poavalidatormanager.ValidatorMessagesMetaData, poavalidatormanager.ValidatorMessagesMetaData.Bin, messagesAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ValidatorMessages bytecode: %w", err)
+	}
+
+	if !strings.Contains(managerMetaData.Bin, validatorMessagesPlaceholder) {
+		return nil, fmt.Errorf("the %s validator manager bytecode doesn't link ValidatorMessages at %s", validatorType, validatorMessagesPlaceholder)
+	}
+	linkedBin := strings.ReplaceAll(managerMetaData.Bin, validatorMessagesPlaceholder, messagesAddress.Hex()[2:])
+	// Allowed, like deploy-validator-manager, so the proxy can initialize it
+	managerCode, err := deployedBytecode(managerMetaData, linkedBin, common.HexToAddress(config.ValidatorManagerImplementationAddress), uint8(0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s validator manager bytecode: %w", validatorType, err)
+	}
+
+	alloc := types.GenesisAlloc{
+		messagesAddress: {
+			Balance: big.NewInt(0),
+			Code:    messagesCode,
+			Nonce:   1,
+		},
+		common.HexToAddress(config.ValidatorManagerImplementationAddress): {
+			Balance: big.NewInt(0),
+			Code:    managerCode,
+			Nonce:   1,
+		},
+	}
+	if validatorType == config.PoSNativeMode {
+		rewardCalculatorAddress := common.HexToAddress(config.RewardCalculatorAddress)
+		rewardCalculatorCode, err := deployedBytecode(examplerewardcalculator.ExampleRewardCalculatorMetaData, examplerewardcalculator.ExampleRewardCalculatorMetaData.Bin, rewardCalculatorAddress, uint64(0))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build reward calculator bytecode: %w", err)
+		}
+		alloc[rewardCalculatorAddress] = types.Account{
+			Balance: big.NewInt(0),
+			Code:    rewardCalculatorCode,
+			Nonce:   1,
+		}
+	}
+	return alloc, nil
+}
+
+// deployedBytecode runs the constructor bin of a contract in an in-memory EVM
+// and returns the code it deploys, to be placed at address in the genesis
+func deployedBytecode(metaData *bind.MetaData, bin string, address common.Address, constructorArgs ...interface{}) ([]byte, error) {
+	parsed, err := metaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	args, err := parsed.Pack("", constructorArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack constructor arguments: %w", err)
+	}
+
+	code, created, _, err := runtime.Create(append(common.FromHex(bin), args...), &runtime.Config{
+		ChainConfig: genesisEVMChainConfig(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("constructor failed: %w", err)
+	}
+
+	// A library guards against direct calls by comparing with the address it
+	// was deployed at, which its constructor pushes at the very start
+	if len(code) > 21 && code[0] == 0x73 && bytes.Equal(code[1:21], created.Bytes()) {
+		copy(code[1:21], address.Bytes())
+	}
+	return code, nil
+}
+
+// genesisEVMChainConfig runs constructors with every network upgrade active,
+// like on a new L1
+func genesisEVMChainConfig() *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainID:             big.NewInt(1),
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         big.NewInt(0),
+		EIP155Block:         big.NewInt(0),
+		EIP158Block:         big.NewInt(0),
+		ByzantiumBlock:      big.NewInt(0),
+		ConstantinopleBlock: big.NewInt(0),
+		PetersburgBlock:     big.NewInt(0),
+		IstanbulBlock:       big.NewInt(0),
+		MuirGlacierBlock:    big.NewInt(0),
+		NetworkUpgrades: params.NetworkUpgrades{
+			SubnetEVMTimestamp: utils.NewUint64(0),
+			DurangoTimestamp:   utils.NewUint64(0),
+			EtnaTimestamp:      utils.NewUint64(0),
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+var increaseBalanceAmount string
+
+func init() {
+	increaseBalanceCmd.Flags().StringVar(&increaseBalanceAmount, "amount", "", "AVAX to add to the balance of the validator, like 1.5 (required)")
+	increaseBalanceCmd.MarkFlagRequired("amount")
+	addUnsignedOutFlag(increaseBalanceCmd)
+	rootCmd.AddCommand(increaseBalanceCmd)
+}
+
+var increaseBalanceCmd = &cobra.Command{
+	Use:   "increase-balance <NodeID|ValidationID>",
+	Short: "Add AVAX to the continuous fee balance of an L1 validator",
+	Long: `Add AVAX to the continuous fee balance of an L1 validator.
+
+An L1 validator pays a continuous fee to the P-chain out of its balance and
+is deactivated once the balance runs out. The fee payer tops it up with
+--amount, which also reactivates a deactivated validator. A NodeID must be
+a validator recorded in the manifest, any other validator is given by its
+validation ID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		amount, err := parseAVAXAmount(increaseBalanceAmount)
+		if err != nil {
+			return err
+		}
+
+		PrintHeader("⛽ Increasing L1 validator balance")
+
+		validationID, err := resolveValidationID(args[0])
+		if err != nil {
+			return err
+		}
+		pClient := platformvm.NewClient(currentNetwork.PChainURI)
+		validator, _, err := pClient.GetL1Validator(context.Background(), validationID)
+		if err != nil {
+			return fmt.Errorf("failed to get L1 validator %s: %w", validationID, err)
+		}
+		log.Printf("Validator %s of subnet %s has %s AVAX left\n", validator.NodeID, validator.SubnetID, formatAVAX(validator.Balance))
+
+		if err := checkIncreaseBalanceFunds(amount); err != nil {
+			return err
+		}
+
+		kc, _, err := pChainKeychain(helpers.RoleFeePayer)
+		if err != nil {
+			return err
+		}
+		wallet, err := pChainWallet(kc, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		unsignedTx, err := wallet.Builder().NewIncreaseL1ValidatorBalanceTx(validationID, amount)
+		if err != nil {
+			return fmt.Errorf("failed to build increase balance tx: %w", err)
+		}
+		tx, err := issuePChainTx(wallet, unsignedTx)
+		if err != nil {
+			return err
+		}
+		if tx == nil {
+			return nil
+		}
+		log.Printf("✅ Added %s AVAX to the balance of %s in tx %s\n", formatAVAX(amount), validator.NodeID, tx.ID())
+
+		validator, _, err = pClient.GetL1Validator(context.Background(), validationID)
+		if err != nil {
+			return fmt.Errorf("failed to get L1 validator %s: %w", validationID, err)
+		}
+		log.Printf("Balance is now %s AVAX\n", formatAVAX(validator.Balance))
+		return nil
+	},
+}
+
+// resolveValidationID parses a validation ID, or looks up the one of a
+// NodeID in the manifest
+func resolveValidationID(arg string) (ids.ID, error) {
+	if strings.HasPrefix(arg, ids.NodeIDPrefix) {
+		nodeID, err := ids.NodeIDFromString(arg)
+		if err != nil {
+			return ids.Empty, fmt.Errorf("failed to parse node ID: %w", err)
+		}
+		manifest, err := helpers.LoadManifest()
+		if err != nil {
+			return ids.Empty, fmt.Errorf("failed to load manifest: %w", err)
+		}
+		validator, ok := manifest.Validator(nodeID)
+		if !ok || validator.ValidationID == ids.Empty {
+			return ids.Empty, fmt.Errorf("node %s is not a validator of this workspace, pass its validation ID instead", nodeID)
+		}
+		return validator.ValidationID, nil
+	}
+
+	validationID, err := ids.FromString(arg)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("%q is neither a NodeID nor a validation ID: %w", arg, err)
+	}
+	return validationID, nil
+}
+
+// validatorBalance parses a --balance flag
+func validatorBalance(flag string) (uint64, error) {
+	balance, err := parseAVAXAmount(flag)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --balance: %w", err)
+	}
+	return balance, nil
+}
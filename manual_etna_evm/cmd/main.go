@@ -10,4 +10,13 @@ func Execute() error {
 
 var rootCmd = &cobra.Command{
 	Use: "manual_etna_evm",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadWorkspace(); err != nil {
+			return err
+		}
+		if cmd.Annotations[offlineAnnotation] != "" {
+			return nil
+		}
+		return loadNetwork()
+	},
 }
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+
+	nativetokenstakingmanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/NativeTokenStakingManager"
+	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
+)
+
+var (
+	managerArtifactPath    string
+	managerLibraries       []string
+	managerConstructorArgs string
+)
+
+// managerFlowMethods are the calls the add and remove validator steps and
+// initialize-validator-set make to the validator manager
+var managerFlowMethods = []string{
+	"initializeValidatorSet((bytes32,bytes32,address,(bytes,bytes,uint64)[]),uint32)",
+	"initializeValidatorRegistration((bytes,bytes,uint64,(uint32,address[]),(uint32,address[])),uint64)",
+	"completeValidatorRegistration(uint32)",
+	"initializeEndValidation(bytes32)",
+	"completeEndValidation(uint32)",
+}
+
+// checkManagerABI fails unless a custom manager has every method the steps
+// call, with the same parameters, and the initialize of validatorType
+func checkManagerABI(artifact *helpers.ContractArtifact, validatorType string) error {
+	var bindingMetaData *bind.MetaData
+	switch validatorType {
+	case config.PoAMode:
+		bindingMetaData = poavalidatormanager.PoAValidatorManagerMetaData
+	case config.PoSNativeMode:
+		bindingMetaData = nativetokenstakingmanager.NativeTokenStakingManagerMetaData
+	default:
+		return fmt.Errorf("invalid validator type: %s. Must be either '%s' or '%s'", validatorType, config.PoAMode, config.PoSNativeMode)
+	}
+	binding, err := bindingMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	required := append([]string{binding.Methods["initialize"].Sig}, managerFlowMethods...)
+
+	signatures := map[string]bool{}
+	for _, method := range artifact.ABI.Methods {
+		signatures[method.Sig] = true
+	}
+	missing := []string{}
+	for _, sig := range required {
+		if !signatures[sig] {
+			missing = append(missing, sig)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s can't be used as a %s validator manager, its ABI is missing:\n  %s", artifact.Name, validatorType, strings.Join(missing, "\n  "))
+	}
+	return nil
+}
+
+// deployManagerArtifact deploys the libraries given as artifacts, then the
+// manager linked with them. The manager has to land where the proxy points,
+// which is checked against the nonce of the owner before sending anything.
+func deployManagerArtifact(opts *bind.TransactOpts, ethClient ethclient.Client, owner common.Address, expected common.Address) (common.Address, *types.Transaction, error) {
+	manager, err := helpers.LoadContractArtifact(managerArtifactPath)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	if err := checkManagerABI(manager, validatorType); err != nil {
+		return common.Address{}, nil, err
+	}
+	args, err := manager.ConstructorArgs(managerConstructorArgs)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	// Both maps are keyed by the fully qualified name of the library
+	libraries := map[string]common.Address{}
+	libraryArtifacts := map[string]*helpers.ContractArtifact{}
+	for _, value := range managerLibraries {
+		given, target, ok := strings.Cut(value, "=")
+		if !ok {
+			return common.Address{}, nil, fmt.Errorf("--library %q must look like <name>=<0xaddress|artifact.json>", value)
+		}
+		name, err := manager.ResolveLibrary(given)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		if _, ok := libraries[name]; ok || libraryArtifacts[name] != nil {
+			return common.Address{}, nil, fmt.Errorf("library %s is given more than once", name)
+		}
+		if common.IsHexAddress(target) {
+			libraries[name] = common.HexToAddress(target)
+			continue
+		}
+		library, err := helpers.LoadContractArtifact(target)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		if len(library.Libraries()) > 0 {
+			return common.Address{}, nil, fmt.Errorf("library %s links %v itself, deploy it first and pass its address", name, library.Libraries())
+		}
+		libraryArtifacts[name] = library
+	}
+	for _, name := range manager.Libraries() {
+		if _, ok := libraries[name]; !ok && libraryArtifacts[name] == nil {
+			return common.Address{}, nil, fmt.Errorf("%s links library %s, pass --library %s=<0xaddress|artifact.json>", manager.Name, name, name)
+		}
+	}
+
+	// Link once with stand-in addresses to catch mistakes before deploying
+	standIns := map[string]common.Address{}
+	for name, address := range libraries {
+		standIns[name] = address
+	}
+	for name := range libraryArtifacts {
+		standIns[name] = common.Address{}
+	}
+	if _, err := manager.Link(standIns); err != nil {
+		return common.Address{}, nil, err
+	}
+
+	nonce, err := ethClient.NonceAt(context.Background(), owner, nil)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to get owner nonce: %w", err)
+	}
+	managerNonce := nonce + uint64(len(libraryArtifacts))
+	if MustDeriveContractAddress(owner, managerNonce) != expected {
+		proxyNonce, ok := contractNonce(owner, expected, managerNonce+1)
+		if !ok {
+			return common.Address{}, nil, fmt.Errorf("the proxy points at %s, which isn't where the owner %s deploys to at any nonce up to %d; use generate-genesis --manager-in-genesis", expected, owner, managerNonce+1)
+		}
+		return common.Address{}, nil, fmt.Errorf("the owner is at nonce %d, so after %d library deployments the manager would land at nonce %d instead of %d where the proxy points; deploy or pass libraries so that the manager is the owner's transaction at nonce %d, or use generate-genesis --manager-in-genesis", nonce, len(libraryArtifacts), managerNonce, proxyNonce, proxyNonce)
+	}
+
+	for name, library := range libraryArtifacts {
+		code, err := library.Link(nil)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		address, tx, _, err := bind.DeployContract(opts, library.ABI, code, ethClient)
+		if err != nil {
+			return common.Address{}, nil, fmt.Errorf("failed to deploy library %s: %w", name, err)
+		}
+		if err := waitDeployed(ethClient, tx); err != nil {
+			return common.Address{}, nil, fmt.Errorf("failed to deploy library %s: %w", name, err)
+		}
+		log.Printf("Deployed library %s at %s\n", name, address)
+		libraries[name] = address
+	}
+
+	code, err := manager.Link(libraries)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	address, tx, _, err := bind.DeployContract(opts, manager.ABI, code, ethClient, args...)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to deploy %s: %w", manager.Name, err)
+	}
+	log.Printf("Deploying %s from %s\n", manager.Name, managerArtifactPath)
+	return address, tx, nil
+}
+
+func waitDeployed(ethClient ethclient.Client, tx *types.Transaction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, ethClient, tx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for transaction confirmation: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s failed", tx.Hash())
+	}
+	return nil
+}
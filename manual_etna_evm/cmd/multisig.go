@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+// subnetOwners returns the control keys of a subnet in the order the P-chain
+// indexes them for subnet auth, and how many of them have to sign
+func subnetOwners(subnetID ids.ID) ([]ids.ShortID, uint32, error) {
+	pClient := platformvm.NewClient(currentNetwork.PChainURI)
+	subnet, err := pClient.GetSubnet(context.Background(), subnetID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get subnet %s: %w", subnetID, err)
+	}
+	return subnet.ControlKeys, subnet.Threshold, nil
+}
+
+// pickSubnetAuthKeys chooses threshold owners to sign, the ones in kc first
+func pickSubnetAuthKeys(owners []ids.ShortID, threshold uint32, kc keychain.Keychain) []ids.ShortID {
+	local := kc.Addresses()
+	authKeys := []ids.ShortID{}
+	for _, owner := range owners {
+		if local.Contains(owner) && uint32(len(authKeys)) < threshold {
+			authKeys = append(authKeys, owner)
+		}
+	}
+	for _, owner := range owners {
+		if !local.Contains(owner) && uint32(len(authKeys)) < threshold {
+			authKeys = append(authKeys, owner)
+		}
+	}
+	return authKeys
+}
+
+// issueSubnetAuthTx issues a tx that needs the signatures of the subnet
+// owners. If the keys of the workspace aren't enough, the partially signed tx
+// is saved to pendingPath for sign, and the next call issues it once every
+// signature is there. validate checks that a tx found at pendingPath is the
+// one the caller is about to build. With --unsigned-out the tx is saved
+// unsigned instead, and a nil tx is returned.
+func issueSubnetAuthTx(
+	wallet *helpers.PChainWallet,
+	kc keychain.Keychain,
+	feePayer ids.ShortID,
+	subnetID ids.ID,
+	pendingPath string,
+	validate func(*txs.Tx) error,
+	build func(options ...common.Option) (txs.UnsignedTx, error),
+) (*txs.Tx, error) {
+	ctx := context.Background()
+	pending, err := helpers.FileExists(pendingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var offlineTx *helpers.OfflineTx
+	if pending && unsignedOutPath == "" {
+		offlineTx, err = helpers.LoadOfflineTx(pendingPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pending tx: %w", err)
+		}
+		if err := validate(offlineTx.Tx()); err != nil {
+			return nil, fmt.Errorf("pending tx %s doesn't match, delete it to build a new one: %w", pendingPath, err)
+		}
+		log.Printf("Loaded pending tx %s from %s\n", offlineTx.Tx().ID(), pendingPath)
+		// Sign again in case the subnet owner key of the workspace was added since
+		if err := offlineTx.Sign(ctx, kc); err != nil {
+			return nil, fmt.Errorf("failed to sign tx: %w", err)
+		}
+	} else {
+		owners, threshold, err := subnetOwners(subnetID)
+		if err != nil {
+			return nil, err
+		}
+		authKeys := pickSubnetAuthKeys(owners, threshold, kc)
+		unsignedTx, err := build(getMultisigTxOptions(authKeys, feePayer, kc)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build tx: %w", err)
+		}
+		tx := &txs.Tx{Unsigned: unsignedTx}
+		if unsignedOutPath != "" {
+			return nil, saveUnsignedPChainTx(ctx, wallet, tx, unsignedOutPath)
+		}
+		if err := wallet.Signer().Sign(ctx, tx); err != nil {
+			return nil, fmt.Errorf("failed to sign tx: %w", err)
+		}
+		offlineTx, err = helpers.NewOfflineTx(ctx, wallet.NetworkID, tx, wallet.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export tx: %w", err)
+		}
+	}
+
+	remaining, err := offlineTx.RemainingSigners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(remaining) > 0 {
+		if err := offlineTx.Save(pendingPath); err != nil {
+			return nil, fmt.Errorf("failed to save pending tx: %w", err)
+		}
+		formatted, err := formatPChainAddresses(offlineTx.NetworkID(), remaining)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("tx %s needs %d more signature(s) from %s: run `sign %s` with their keys, then run this command again",
+			offlineTx.Tx().ID(), len(remaining), strings.Join(formatted, ", "), pendingPath)
+	}
+
+	tx, err := broadcastPChainTx(ctx, offlineTx)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		if err := os.Remove(pendingPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
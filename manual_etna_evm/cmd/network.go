@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-cli/cmd/blockchaincmd"
+	"github.com/ava-labs/avalanche-cli/pkg/models"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+var (
+	networkName        string
+	customRPCURL       string
+	customNetworkID    uint32
+	aggregatorPeerURIs []string
+	currentNetwork     config.Network
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&networkName, "network", config.FujiNetwork, fmt.Sprintf("Network profile to use (%s)", strings.Join(config.NetworkNames(), ", ")))
+	rootCmd.PersistentFlags().StringVar(&customRPCURL, "rpc-url", "", "Primary network API URL, required for the custom network and overrides the profile otherwise")
+	rootCmd.PersistentFlags().Uint32Var(&customNetworkID, "network-id", 0, "Network ID of the custom network (queried from --rpc-url when omitted)")
+	rootCmd.PersistentFlags().StringSliceVar(&aggregatorPeerURIs, "aggregator-peers", nil, "Node URIs the signature aggregator connects to (overrides the profile)")
+}
+
+// offlineAnnotation marks commands that never talk to the primary network,
+// so the network isn't resolved for them
+const offlineAnnotation = "offline"
+
+var offline = map[string]string{offlineAnnotation: "true"}
+
+// loadNetwork resolves the --network flag and its overrides into currentNetwork
+func loadNetwork() error {
+	var network config.Network
+	if networkName == config.CustomNetwork {
+		if customRPCURL == "" {
+			return fmt.Errorf("--rpc-url is required for the %s network", config.CustomNetwork)
+		}
+		networkID := customNetworkID
+		if networkID == 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			id, err := info.NewClient(customRPCURL).GetNetworkID(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get network ID from %s: %w", customRPCURL, err)
+			}
+			networkID = id
+		}
+		network = config.NewCustomNetwork(customRPCURL, networkID)
+	} else {
+		var err error
+		network, err = config.GetNetwork(networkName)
+		if err != nil {
+			return err
+		}
+		if customRPCURL != "" {
+			network.PChainURI = strings.TrimSuffix(customRPCURL, "/")
+			network.CChainURL = network.PChainURI + "/ext/bc/C/rpc"
+		}
+		if network.PChainURI == "" {
+			return fmt.Errorf("--rpc-url is required for the %s network, its nodes can't share the node ports of the workspaces", network.Name)
+		}
+	}
+
+	if len(aggregatorPeerURIs) > 0 {
+		network.AggregatorPeers = aggregatorPeerURIs
+	}
+
+	currentNetwork = network
+	return nil
+}
+
+// aggregatorNetwork converts the current profile into the network type used by the avalanche-cli signature aggregator
+func aggregatorNetwork() models.Network {
+	var kind models.NetworkKind
+	switch currentNetwork.Name {
+	case config.FujiNetwork:
+		kind = models.Fuji
+	case config.MainnetNetwork:
+		kind = models.Mainnet
+	case config.LocalNetwork:
+		kind = models.Local
+	default:
+		kind = models.Devnet
+	}
+	return models.NewNetwork(kind, currentNetwork.NetworkID, currentNetwork.PChainURI, "")
+}
+
+// aggregatorPeers returns node0 of the current workspace followed by the extra peers of the network profile
+// and the validators recorded with a node URI
+func aggregatorPeers() ([]info.Peer, error) {
+	node0URI, err := helpers.NodeURI(0)
+	if err != nil {
+		return nil, err
+	}
+	uris := []string{node0URI}
+	for _, uri := range currentNetwork.AggregatorPeers {
+		if uri != node0URI {
+			uris = append(uris, uri)
+		}
+	}
+	// Validators running outside the workspace have to sign too
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	for _, validator := range manifest.Validators {
+		if validator.NodeURI != "" && !validator.Removed && !slices.Contains(uris, validator.NodeURI) {
+			uris = append(uris, validator.NodeURI)
+		}
+	}
+	peers, err := blockchaincmd.ConvertURIToPeers(uris)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aggregator peers: %w", err)
+	}
+	return peers, nil
+}
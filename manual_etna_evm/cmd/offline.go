@@ -0,0 +1,451 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	goethereumcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	unsignedOutPath string
+	signKeyFile     string
+)
+
+func init() {
+	signCmd.Flags().StringVar(&signKeyFile, "key-file", "", "Sign with the hex encoded private key in this file instead of the role keys of the workspace")
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(broadcastCmd)
+}
+
+// addUnsignedOutFlag lets cmd save its P-chain tx for sign and broadcast
+// instead of signing and issuing it
+func addUnsignedOutFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&unsignedOutPath, "unsigned-out", "", "Save the unsigned P-chain tx and the UTXOs it spends to this file instead of issuing it, see sign and broadcast")
+}
+
+var signCmd = &cobra.Command{
+	Use:         "sign <file>",
+	Annotations: offline,
+	Aliases:     []string{"sign-tx"},
+	Short:       "Add your signatures to a saved P-chain tx, without network access",
+	Long: `Add your signatures to a saved P-chain tx, without network access.
+
+The file is written by --unsigned-out, or by create-chain and convert-to-L1
+when the subnet needs more signatures than the keys of the workspace can
+provide. It holds the UTXOs and owners the tx spends, so it can be signed on a
+machine that never goes online. Once every signature is there, issue it with
+broadcast.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		PrintHeader("✍️  Signing P-chain transaction")
+
+		txPath := args[0]
+		offlineTx, err := helpers.LoadOfflineTx(txPath)
+		if err != nil {
+			return fmt.Errorf("failed to load tx: %w", err)
+		}
+		kc, err := signKeychain()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		before, err := offlineTx.RemainingSigners(ctx)
+		if err != nil {
+			return err
+		}
+		if err := offlineTx.Sign(ctx, kc); err != nil {
+			return fmt.Errorf("failed to sign tx: %w", err)
+		}
+		remaining, err := offlineTx.RemainingSigners(ctx)
+		if err != nil {
+			return err
+		}
+		if len(remaining) == len(before) {
+			formatted, err := formatPChainAddresses(offlineTx.NetworkID(), before)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("none of the keys here can sign tx %s, it waits for %s", offlineTx.Tx().ID(), strings.Join(formatted, ", "))
+		}
+		if err := offlineTx.Save(txPath); err != nil {
+			return fmt.Errorf("failed to save tx: %w", err)
+		}
+		log.Printf("✅ Added %d signature(s) to tx %s\n", len(before)-len(remaining), offlineTx.Tx().ID())
+
+		if len(remaining) > 0 {
+			formatted, err := formatPChainAddresses(offlineTx.NetworkID(), remaining)
+			if err != nil {
+				return err
+			}
+			log.Printf("Still waiting for %s\n", strings.Join(formatted, ", "))
+		} else {
+			log.Printf("Tx is fully signed, issue it with `broadcast %s`\n", txPath)
+		}
+		return nil
+	},
+}
+
+var broadcastCmd = &cobra.Command{
+	Use:   "broadcast <file>",
+	Short: "Issue a P-chain tx signed with sign",
+	Long: `Issue a P-chain tx signed with sign.
+
+The workspace records the outcome of the tx as if the command that saved it
+had issued it: the subnet ID of create-subnet, the chain ID of create-chain
+and the conversion of convert-to-L1.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		PrintHeader("📡 Broadcasting P-chain transaction")
+
+		offlineTx, err := helpers.LoadOfflineTx(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to load tx: %w", err)
+		}
+		tx, err := broadcastPChainTx(context.Background(), offlineTx)
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Tx %s is committed\n", tx.ID())
+		return recordPChainTx(tx)
+	},
+}
+
+// signKeychain holds the --key-file key, or the P-chain role keys of the
+// workspace
+func signKeychain() (keychain.Keychain, error) {
+	if signKeyFile != "" {
+		key, err := helpers.LoadSecp256k1PrivateKey(signKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key from %s: %w", signKeyFile, err)
+		}
+		return helpers.NewSignerKeychain(helpers.NewLocalSigner(key)), nil
+	}
+	signers := []helpers.Signer{}
+	for _, role := range helpers.Roles {
+		if role.EVM {
+			continue
+		}
+		signer, err := roleSigner(role)
+		if errors.Is(err, errRoleWithoutKey) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	return helpers.NewSignerKeychain(signers...), nil
+}
+
+// pChainKeychain returns the keychain paying for and authorizing a P-chain
+// tx, the first role paying the fees. The other roles only sign if they have
+// a key here. With --unsigned-out the keychain only needs the addresses of
+// the roles, their keys may be on another machine.
+func pChainKeychain(feePayerRole helpers.Role, authRoles ...helpers.Role) (keychain.Keychain, ids.ShortID, error) {
+	if unsignedOutPath != "" {
+		addrs := []ids.ShortID{}
+		for _, role := range append([]helpers.Role{feePayerRole}, authRoles...) {
+			addr, err := roleAddress(role)
+			if err != nil {
+				return nil, ids.ShortEmpty, err
+			}
+			addrs = append(addrs, addr)
+		}
+		return helpers.NewAddressKeychain(addrs...), addrs[0], nil
+	}
+
+	feePayer, err := roleSigner(feePayerRole)
+	if errors.Is(err, errRoleWithoutKey) {
+		return nil, ids.ShortEmpty, fmt.Errorf("%w: save the tx with --unsigned-out and sign it where the key is", err)
+	}
+	if err != nil {
+		return nil, ids.ShortEmpty, err
+	}
+	signers := []helpers.Signer{feePayer}
+	for _, role := range authRoles {
+		signer, err := roleSigner(role)
+		if errors.Is(err, errRoleWithoutKey) {
+			continue
+		}
+		if err != nil {
+			return nil, ids.ShortEmpty, err
+		}
+		signers = append(signers, signer)
+	}
+	return helpers.NewSignerKeychain(signers...), feePayer.Address(), nil
+}
+
+func pChainWallet(kc keychain.Keychain, subnetIDs []ids.ID, validationIDs []ids.ID) (*helpers.PChainWallet, error) {
+	walletSyncStartTime := time.Now()
+	wallet, err := helpers.NewPChainWallet(context.Background(), currentNetwork.PChainURI, kc, subnetIDs, validationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize wallet: %w", err)
+	}
+	log.Printf("Synced wallet in %s\n", time.Since(walletSyncStartTime))
+	return wallet, nil
+}
+
+// issuePChainTx signs and issues utx. With --unsigned-out it saves utx for
+// sign and broadcast instead, and returns a nil tx.
+func issuePChainTx(wallet *helpers.PChainWallet, utx txs.UnsignedTx) (*txs.Tx, error) {
+	ctx := context.Background()
+	tx := &txs.Tx{Unsigned: utx}
+	if unsignedOutPath != "" {
+		return nil, saveUnsignedPChainTx(ctx, wallet, tx, unsignedOutPath)
+	}
+	if err := wallet.Signer().Sign(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to sign tx: %w", err)
+	}
+	if err := wallet.IssueTx(tx); err != nil {
+		return nil, fmt.Errorf("failed to issue tx: %w", err)
+	}
+	return tx, nil
+}
+
+// issuePChainTxAndWait is issuePChainTx for commands that go on once the tx
+// is accepted. With --unsigned-out it waits for the signed tx to be copied
+// back, then broadcasts it.
+func issuePChainTxAndWait(wallet *helpers.PChainWallet, utx txs.UnsignedTx) (*txs.Tx, error) {
+	tx, err := issuePChainTx(wallet, utx)
+	if err != nil || tx != nil {
+		return tx, err
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("waiting for %s to be signed needs a terminal", unsignedOutPath)
+	}
+
+	ctx := context.Background()
+	stdin := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stderr, "Sign %s, copy it back to the same path and press Enter: ", unsignedOutPath)
+		if _, err := stdin.ReadString('\n'); err != nil {
+			return nil, fmt.Errorf("failed to read from stdin: %w", err)
+		}
+		offlineTx, err := helpers.LoadOfflineTx(unsignedOutPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tx: %w", err)
+		}
+		if !bytes.Equal(offlineTx.Tx().Unsigned.Bytes(), utx.Bytes()) {
+			log.Printf("❌ %s holds another tx\n", unsignedOutPath)
+			continue
+		}
+		tx, err := broadcastPChainTx(ctx, offlineTx)
+		if err != nil {
+			log.Printf("❌ %s\n", err)
+			continue
+		}
+		return tx, nil
+	}
+}
+
+func saveUnsignedPChainTx(ctx context.Context, wallet *helpers.PChainWallet, tx *txs.Tx, path string) error {
+	offlineTx, err := helpers.NewOfflineTx(ctx, wallet.NetworkID, tx, wallet.Backend)
+	if err != nil {
+		return fmt.Errorf("failed to export tx: %w", err)
+	}
+	if err := offlineTx.Save(path); err != nil {
+		return fmt.Errorf("failed to save tx: %w", err)
+	}
+	log.Printf("Saved unsigned tx %s to %s\n", tx.ID(), path)
+	log.Printf("Run `sign %s` where the keys are, then `broadcast %s`\n", path, path)
+	return nil
+}
+
+// broadcastPChainTx issues a fully signed tx and waits for it to be committed.
+// A tx committed already is returned as is.
+func broadcastPChainTx(ctx context.Context, offlineTx *helpers.OfflineTx) (*txs.Tx, error) {
+	tx := offlineTx.Tx()
+	if offlineTx.NetworkID() != currentNetwork.NetworkID {
+		return nil, fmt.Errorf("tx %s was built for network %d, not %d", tx.ID(), offlineTx.NetworkID(), currentNetwork.NetworkID)
+	}
+
+	pClient := platformvm.NewClient(currentNetwork.PChainURI)
+	txStatus, err := pClient.GetTxStatus(ctx, tx.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of tx %s: %w", tx.ID(), err)
+	}
+	if txStatus.Status == status.Committed {
+		log.Printf("Tx %s was already committed\n", tx.ID())
+		return tx, nil
+	}
+
+	remaining, err := offlineTx.RemainingSigners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(remaining) > 0 {
+		formatted, err := formatPChainAddresses(offlineTx.NetworkID(), remaining)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("tx %s still needs the signature of %s", tx.ID(), strings.Join(formatted, ", "))
+	}
+
+	if _, err := pClient.IssueTx(ctx, tx.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to issue tx %s: %w", tx.ID(), err)
+	}
+	if err := platformvm.AwaitTxAccepted(pClient, ctx, tx.ID(), time.Second); err != nil {
+		return nil, fmt.Errorf("failed to wait for tx %s: %w", tx.ID(), err)
+	}
+	txStatus, err = pClient.GetTxStatus(ctx, tx.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of tx %s: %w", tx.ID(), err)
+	}
+	if txStatus.Status != status.Committed {
+		return nil, fmt.Errorf("tx %s was %s: %s", tx.ID(), txStatus.Status, txStatus.Reason)
+	}
+	return tx, nil
+}
+
+// recordPChainTx saves what a committed tx changed in the manifest
+func recordPChainTx(tx *txs.Tx) error {
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	switch utx := tx.Unsigned.(type) {
+	case *txs.CreateSubnetTx:
+		if manifest.SubnetID != ids.Empty && manifest.SubnetID != tx.ID() {
+			return fmt.Errorf("workspace already has subnet %s", manifest.SubnetID)
+		}
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			manifest.SubnetID = tx.ID()
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save subnet ID: %w", err)
+		}
+		log.Printf("Saved subnet ID %s to manifest\n", tx.ID())
+
+	case *txs.CreateChainTx:
+		if utx.SubnetID != manifest.SubnetID {
+			return fmt.Errorf("chain %s belongs to subnet %s, not to subnet %s of the workspace", tx.ID(), utx.SubnetID, manifest.SubnetID)
+		}
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			manifest.ChainID = tx.ID()
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save chain ID: %w", err)
+		}
+		log.Printf("Saved chain ID %s to manifest\n", tx.ID())
+
+	case *txs.ConvertSubnetToL1Tx:
+		if utx.Subnet != manifest.SubnetID {
+			return fmt.Errorf("tx %s converts subnet %s, not subnet %s of the workspace", tx.ID(), utx.Subnet, manifest.SubnetID)
+		}
+		// The conversion is committed, so it is recorded before anything
+		// else can fail
+		conversionData := helpers.ConversionDataFromTx(utx)
+		conversionID, err := conversionData.ID()
+		if err != nil {
+			return err
+		}
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			manifest.ConversionTxID = tx.ID()
+			manifest.ConversionID = conversionID
+			manifest.ConversionData = conversionData
+			manifest.ManagerAddress = goethereumcommon.BytesToAddress(utx.Address)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save conversion ID: %w", err)
+		}
+		log.Printf("Saved conversion %s of tx %s to manifest\n", conversionID, tx.ID())
+
+		validators, err := conversionValidators(utx)
+		if err != nil {
+			return err
+		}
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			for _, validator := range validators {
+				if recorded, ok := manifest.Validator(validator.NodeID); ok {
+					validator.NodeURI = recorded.NodeURI
+					if validator.CredsFolder == "" {
+						validator.CredsFolder = recorded.CredsFolder
+					}
+				}
+				manifest.SetValidator(validator)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save bootstrap validators: %w", err)
+		}
+		log.Printf("Saved %d bootstrap validators to manifest\n", len(validators))
+
+	case *txs.DisableL1ValidatorTx:
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			for i := range manifest.Validators {
+				if manifest.Validators[i].ValidationID == utx.ValidationID {
+					manifest.Validators[i].Disabled = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record disabled validator: %w", err)
+		}
+		return reportDisabledBalance(tx, utx)
+	}
+	return nil
+}
+
+// conversionValidators lists the bootstrap validators of a conversion tx,
+// from the tx alone. Node0 gets its creds folder when this workspace has it.
+func conversionValidators(utx *txs.ConvertSubnetToL1Tx) ([]helpers.ManifestValidator, error) {
+	node0ID, _, err := NodeInfoFromCreds(helpers.Node0KeysFolder)
+	if err != nil {
+		node0ID = ids.EmptyNodeID
+	}
+	validators := make([]helpers.ManifestValidator, len(utx.Validators))
+	for i, validator := range utx.Validators {
+		nodeID, err := ids.ToNodeID(validator.NodeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse node ID of validator %d: %w", i, err)
+		}
+		validators[i] = helpers.ManifestValidator{
+			NodeID:       nodeID,
+			ValidationID: utx.Subnet.Append(uint32(i)),
+			Weight:       validator.Weight,
+			Balance:      validator.Balance,
+			Bootstrap:    true,
+		}
+		if nodeID == node0ID {
+			validators[i].CredsFolder = helpers.Node0KeysFolder
+		}
+	}
+	return validators, nil
+}
+
+func formatPChainAddresses(networkID uint32, addrs []ids.ShortID) ([]string, error) {
+	formatted := make([]string, len(addrs))
+	for i, addr := range addrs {
+		var err error
+		formatted[i], err = address.Format("P", avagoconstants.GetHRP(networkID), addr.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("failed to format P-chain address: %w", err)
+		}
+	}
+	return formatted, nil
+}
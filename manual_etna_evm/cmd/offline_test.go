@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+// inTempDir runs the test from an empty directory, so the relative paths of
+// the default workspace point there
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestRecordConvertTxWithoutCreds(t *testing.T) {
+	inTempDir(t)
+	subnetID := ids.GenerateTestID()
+	if err := helpers.SaveManifest(&helpers.Manifest{SchemaVersion: helpers.ManifestSchemaVersion, SubnetID: subnetID}); err != nil {
+		t.Fatal(err)
+	}
+
+	validators := []*txs.ConvertSubnetToL1Validator{}
+	for i := 0; i < 3; i++ {
+		validators = append(validators, &txs.ConvertSubnetToL1Validator{
+			NodeID:                ids.GenerateTestNodeID().Bytes(),
+			Weight:                uint64(10 * (i + 1)),
+			Balance:               uint64(i+1) * units.Avax,
+			RemainingBalanceOwner: message.PChainOwner{},
+			DeactivationOwner:     message.PChainOwner{},
+		})
+	}
+	utils.Sort(validators)
+	tx := &txs.Tx{Unsigned: &txs.ConvertSubnetToL1Tx{
+		BaseTx:     txs.BaseTx{BaseTx: avax.BaseTx{NetworkID: 5, BlockchainID: ids.Empty}},
+		Subnet:     subnetID,
+		ChainID:    ids.GenerateTestID(),
+		Address:    []byte{0xfe, 0xed},
+		Validators: validators,
+		SubnetAuth: &secp256k1fx.Input{},
+	}}
+	if err := tx.Initialize(txs.Codec); err != nil {
+		t.Fatal(err)
+	}
+
+	// There are no node0 creds here, which must not stop the recording
+	if err := recordPChainTx(tx); err != nil {
+		t.Fatalf("recording conversion: %s", err)
+	}
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.ConversionTxID != tx.ID() {
+		t.Fatalf("conversion tx is %s, want %s", manifest.ConversionTxID, tx.ID())
+	}
+	if manifest.ConversionData == nil || len(manifest.ConversionData.Validators) != len(validators) {
+		t.Fatalf("conversion data wasn't recorded: %+v", manifest.ConversionData)
+	}
+	if id, err := manifest.ConversionData.ID(); err != nil || id != manifest.ConversionID {
+		t.Fatalf("conversion ID is %s, the data hashes to %s (%v)", manifest.ConversionID, id, err)
+	}
+	for i, validator := range validators {
+		nodeID, err := ids.ToNodeID(validator.NodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorded, ok := manifest.Validator(nodeID)
+		if !ok {
+			t.Fatalf("validator %s wasn't recorded", nodeID)
+		}
+		if recorded.ValidationID != subnetID.Append(uint32(i)) || recorded.Weight != validator.Weight || recorded.Balance != validator.Balance || !recorded.Bootstrap {
+			t.Fatalf("validator %d recorded as %+v", i, recorded)
+		}
+		if recorded.CredsFolder != "" {
+			t.Fatalf("validator %s got creds folder %s", nodeID, recorded.CredsFolder)
+		}
+	}
+}
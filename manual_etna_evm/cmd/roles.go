@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+var errRoleWithoutKey = errors.New("no key in this workspace")
+
+var (
+	roleSetAddress  string
+	roleSetKeyFile  string
+	roleSetGenerate bool
+	roleSetEncrypt  bool
+)
+
+func init() {
+	rolesSetCmd.Flags().StringVar(&roleSetAddress, "address", "", "Give the role to this address, P-chain bech32 or 0x hex depending on the role, without a key in this workspace")
+	rolesSetCmd.Flags().StringVar(&roleSetKeyFile, "key-file", "", "Import the key of the role from a file holding a hex encoded private key")
+	rolesSetCmd.Flags().BoolVar(&roleSetGenerate, "generate", false, "Generate a new key for the role")
+	rolesSetCmd.Flags().BoolVar(&roleSetEncrypt, "encrypt", false, fmt.Sprintf("Save the key in an encrypted keystore, unlocked by a prompt or $%s", helpers.KeystorePassphraseEnvVar))
+	rolesSetCmd.MarkFlagsMutuallyExclusive("address", "key-file", "generate")
+	rolesSetCmd.MarkFlagsOneRequired("address", "key-file", "generate")
+
+	rolesCmd.AddCommand(rolesSetCmd)
+	rolesCmd.AddCommand(rolesUnsetCmd)
+	rootCmd.AddCommand(rolesCmd)
+}
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "List who holds each role: fee payer, subnet owner, manager owner...",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifest, err := helpers.LoadManifest()
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		for _, role := range helpers.Roles {
+			holder, err := roleHolder(manifest, role)
+			if err != nil {
+				return err
+			}
+			addr, err := roleAddressString(role)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%-24s %-48s %s (%s)\n", role.Name, addr, holder, role.Description)
+		}
+		return nil
+	},
+}
+
+var rolesSetCmd = &cobra.Command{
+	Use:   "set <role>",
+	Short: "Give a role its own key or address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, err := helpers.RoleByName(args[0])
+		if err != nil {
+			return err
+		}
+
+		if roleSetAddress != "" {
+			if err := validateRoleAddress(role, roleSetAddress); err != nil {
+				return err
+			}
+			if exists, err := helpers.RoleKeyProvider(role).Exists(); err != nil {
+				return err
+			} else if exists {
+				return fmt.Errorf("role %s has a key at %s, delete it first", role.Name, helpers.RoleKeyProvider(role).Path())
+			}
+			err := helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+				if manifest.Roles == nil {
+					manifest.Roles = map[string]string{}
+				}
+				manifest.Roles[role.Name] = roleSetAddress
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save role address: %w", err)
+			}
+			log.Printf("✅ Role %s is held by %s\n", role.Name, roleSetAddress)
+			return nil
+		}
+
+		var key *secp256k1.PrivateKey
+		if roleSetGenerate {
+			key, err = secp256k1.NewPrivateKey()
+			if err != nil {
+				return fmt.Errorf("failed to generate key: %w", err)
+			}
+		} else {
+			key, err = helpers.LoadSecp256k1PrivateKey(roleSetKeyFile)
+			if err != nil {
+				return fmt.Errorf("failed to load key from %s: %w", roleSetKeyFile, err)
+			}
+		}
+		return saveRoleKey(role, key, roleSetEncrypt)
+	},
+}
+
+var rolesUnsetCmd = &cobra.Command{
+	Use:   "unset <role>",
+	Short: "Give a role back to the validator manager owner key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, err := helpers.RoleByName(args[0])
+		if err != nil {
+			return err
+		}
+		if exists, err := helpers.RoleKeyProvider(role).Exists(); err != nil {
+			return err
+		} else if exists {
+			// Keys are never deleted for you
+			return fmt.Errorf("role %s has a key at %s, delete it yourself once it is backed up", role.Name, helpers.RoleKeyProvider(role).Path())
+		}
+		err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+			delete(manifest.Roles, role.Name)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save manifest: %w", err)
+		}
+		log.Printf("✅ Role %s is held by the validator manager owner key\n", role.Name)
+		return nil
+	},
+}
+
+func saveRoleKey(role helpers.Role, key *secp256k1.PrivateKey, encrypt bool) error {
+	if exists, err := helpers.RoleKeyProvider(role).Exists(); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("role %s already has a key at %s", role.Name, helpers.RoleKeyProvider(role).Path())
+	}
+	provider, err := helpers.NewRoleKeyProvider(role, encrypt)
+	if err != nil {
+		return err
+	}
+	if err := provider.Save(key); err != nil {
+		return fmt.Errorf("failed to save %s key: %w", role.Name, err)
+	}
+	// A key replaces any address set before
+	err = helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+		delete(manifest.Roles, role.Name)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+	log.Printf("Saved %s key to %s\n", role.Name, provider.Path())
+	return nil
+}
+
+func validateRoleAddress(role helpers.Role, addr string) error {
+	if role.EVM {
+		if !common.IsHexAddress(addr) {
+			return fmt.Errorf("role %s needs a 0x hex address, got %q", role.Name, addr)
+		}
+		return nil
+	}
+	if _, err := address.ParseToID(addr); err != nil {
+		return fmt.Errorf("role %s needs a P-chain address like P-fuji1..., got %q: %w", role.Name, addr, err)
+	}
+	return nil
+}
+
+func roleHolder(manifest *helpers.Manifest, role helpers.Role) (string, error) {
+	provider := helpers.RoleKeyProvider(role)
+	exists, err := provider.Exists()
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s key: %w", role.Name, err)
+	}
+	switch {
+	case exists:
+		return "key " + provider.Path(), nil
+	case manifest.Roles[role.Name] != "":
+		return "address only", nil
+	default:
+		return "owner key", nil
+	}
+}
+
+// roleSigner signs for role with its own key, or with the owner signer if the
+// role wasn't given to anyone else
+func roleSigner(role helpers.Role) (helpers.Signer, error) {
+	provider := helpers.RoleKeyProvider(role)
+	exists, err := provider.Exists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s key: %w", role.Name, err)
+	}
+	if exists {
+		key, err := provider.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s key: %w", role.Name, err)
+		}
+		return helpers.NewLocalSigner(key), nil
+	}
+
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if addr := manifest.Roles[role.Name]; addr != "" {
+		return nil, fmt.Errorf("role %s is held by %s, which has %w", role.Name, addr, errRoleWithoutKey)
+	}
+	return ownerSigner()
+}
+
+// roleAccount is the account of the key holding role, read like ownerAccount
+// without decrypting a keystore that records its addresses
+func roleAccount(role helpers.Role) (helpers.SignerAccount, error) {
+	provider := helpers.RoleKeyProvider(role)
+	exists, err := provider.Exists()
+	if err != nil {
+		return helpers.SignerAccount{}, fmt.Errorf("failed to check %s key: %w", role.Name, err)
+	}
+	if exists {
+		account, err := provider.Account()
+		if err != nil {
+			return helpers.SignerAccount{}, fmt.Errorf("failed to load %s key: %w", role.Name, err)
+		}
+		return account, nil
+	}
+
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return helpers.SignerAccount{}, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if addr := manifest.Roles[role.Name]; addr != "" {
+		return helpers.SignerAccount{}, fmt.Errorf("role %s is held by %s, which has %w", role.Name, addr, errRoleWithoutKey)
+	}
+	return ownerAccount()
+}
+
+// roleAddress is the P-chain address holding role
+func roleAddress(role helpers.Role) (ids.ShortID, error) {
+	if role.EVM {
+		return ids.ShortEmpty, fmt.Errorf("role %s is held by an EVM address", role.Name)
+	}
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return ids.ShortEmpty, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if exists, err := helpers.RoleKeyProvider(role).Exists(); err == nil && !exists {
+		if addr := manifest.Roles[role.Name]; addr != "" {
+			return address.ParseToID(addr)
+		}
+	}
+	account, err := roleAccount(role)
+	if err != nil {
+		return ids.ShortEmpty, err
+	}
+	return account.Address, nil
+}
+
+// roleEthAddress is the EVM address holding role
+func roleEthAddress(role helpers.Role) (common.Address, error) {
+	if !role.EVM {
+		return common.Address{}, fmt.Errorf("role %s is held by a P-chain address", role.Name)
+	}
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if exists, err := helpers.RoleKeyProvider(role).Exists(); err == nil && !exists {
+		if addr := manifest.Roles[role.Name]; addr != "" {
+			return common.HexToAddress(addr), nil
+		}
+	}
+	account, err := roleAccount(role)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return account.EthAddress, nil
+}
+
+func roleAddressString(role helpers.Role) (string, error) {
+	if role.EVM {
+		addr, err := roleEthAddress(role)
+		if err != nil {
+			return "", err
+		}
+		return addr.Hex(), nil
+	}
+	addr, err := roleAddress(role)
+	if err != nil {
+		return "", err
+	}
+	return formatPChainAddress(addr)
+}
+
+func formatPChainAddress(addr ids.ShortID) (string, error) {
+	formatted, err := address.Format("P", avagoconstants.GetHRP(currentNetwork.NetworkID), addr.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format P-chain address: %w", err)
+	}
+	return formatted, nil
+}
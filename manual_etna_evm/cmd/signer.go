@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ava-labs/avalanche-cli/pkg/contract"
+	"github.com/ava-labs/avalanche-cli/pkg/evm"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ava-labs/subnet-evm/predicate"
+	subnetEvmUtils "github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+const SignerSocketEnvVar = "ETNA_SIGNER_SOCKET"
+
+var (
+	signerSocket      string
+	serveSignerSocket string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&signerSocket, "signer-socket", "", fmt.Sprintf("Sign with the external signer listening on this unix socket instead of the local owner key (defaults to $%s)", SignerSocketEnvVar))
+
+	serveSignerCmd.Flags().StringVar(&serveSignerSocket, "socket", "", "Unix socket to listen on (required)")
+	serveSignerCmd.MarkFlagRequired("socket")
+	rootCmd.AddCommand(serveSignerCmd)
+}
+
+// ownerSigner signs for the validator manager owner, either with the local key
+// or through the external signer selected by --signer-socket
+func ownerSigner() (helpers.Signer, error) {
+	if socket := ownerSignerSocket(); socket != "" {
+		return helpers.NewExternalSigner(socket)
+	}
+
+	key, err := helpers.LoadOwnerKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load validator manager owner key: %w", err)
+	}
+	return helpers.NewLocalSigner(key), nil
+}
+
+// ownerAccount is the account of ownerSigner. An encrypted owner key is only
+// decrypted if its keystore doesn't record its addresses.
+func ownerAccount() (helpers.SignerAccount, error) {
+	if socket := ownerSignerSocket(); socket != "" {
+		signer, err := helpers.NewExternalSigner(socket)
+		if err != nil {
+			return helpers.SignerAccount{}, err
+		}
+		return helpers.SignerAccount{Address: signer.Address(), EthAddress: signer.EthAddress()}, nil
+	}
+
+	account, err := helpers.OwnerKeyProvider().Account()
+	if err != nil {
+		return helpers.SignerAccount{}, fmt.Errorf("failed to load validator manager owner key: %w", err)
+	}
+	return account, nil
+}
+
+func ownerSignerSocket() string {
+	if signerSocket != "" {
+		return signerSocket
+	}
+	return os.Getenv(SignerSocketEnvVar)
+}
+
+var serveSignerCmd = &cobra.Command{
+	Use:         "serve-signer",
+	Annotations: offline,
+	Short:       "Serve the owner key of the workspace as an external signer for --signer-socket",
+	Long: `Serve the owner key of the workspace as an external signer for --signer-socket.
+
+This is a stand-in for a real signer process such as a hardware wallet bridge:
+it signs every hash it is asked to and logs it. The socket speaks JSON-RPC 2.0
+with two methods, signer_account and signer_signHash.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := helpers.LoadOwnerKey()
+		if err != nil {
+			return fmt.Errorf("failed to load validator manager owner key: %w", err)
+		}
+		signer := helpers.NewLocalSigner(key)
+
+		log.Printf("🔏 Serving signer for %s (%s) on %s\n", signer.Address(), signer.EthAddress(), serveSignerSocket)
+		return helpers.ServeSigner(serveSignerSocket, helpers.NewSignerService(signer, log.Printf))
+	},
+}
+
+// txToMethod is contract.TxToMethod with a Signer instead of a private key
+func txToMethod(
+	rpcURL string,
+	signer helpers.Signer,
+	contractAddress common.Address,
+	payment *big.Int,
+	description string,
+	errorSignatureToError map[string]error,
+	methodSpec string,
+	params ...interface{},
+) (*types.Transaction, *types.Receipt, error) {
+	methodName, methodABI, err := contract.ParseSpec(methodSpec, nil, false, false, payment != nil, false, params...)
+	if err != nil {
+		return nil, nil, err
+	}
+	abi, err := (&bind.MetaData{ABI: methodABI}).GetAbi()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.Close()
+	chainID, err := evm.GetChainID(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	boundContract := bind.NewBoundContract(contractAddress, *abi, client, client, client)
+	opts := helpers.NewSignerTransactor(signer, chainID)
+	opts.Value = payment
+	tx, err := boundContract.Transact(opts, methodName, params...)
+	if err != nil {
+		return tx, nil, fmt.Errorf("error on %q: %w", description, err)
+	}
+	return waitForMethodTx(rpcURL, client, tx, description, errorSignatureToError)
+}
+
+// txToMethodWithWarpMessage is contract.TxToMethodWithWarpMessage with a Signer instead of a private key
+func txToMethodWithWarpMessage(
+	rpcURL string,
+	signer helpers.Signer,
+	contractAddress common.Address,
+	warpMessage *avalancheWarp.Message,
+	payment *big.Int,
+	description string,
+	errorSignatureToError map[string]error,
+	methodSpec string,
+	params ...interface{},
+) (*types.Transaction, *types.Receipt, error) {
+	const defaultGasLimit = 2_000_000
+
+	methodName, methodABI, err := contract.ParseSpec(methodSpec, nil, false, false, false, false, params...)
+	if err != nil {
+		return nil, nil, err
+	}
+	abi, err := (&bind.MetaData{ABI: methodABI}).GetAbi()
+	if err != nil {
+		return nil, nil, err
+	}
+	callData, err := abi.Pack(methodName, params...)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.Close()
+
+	address := signer.EthAddress()
+	gasFeeCap, gasTipCap, nonce, err := evm.CalculateTxParams(client, address.Hex())
+	if err != nil {
+		return nil, nil, err
+	}
+	chainID, err := evm.GetChainID(client)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The signed warp message travels in the access list, where the warp precompile verifies it
+	accessList := types.AccessList{
+		types.AccessTuple{
+			Address:     warp.ContractAddress,
+			StorageKeys: subnetEvmUtils.BytesToHashSlice(predicate.PackPredicate(warpMessage.Bytes())),
+		},
+	}
+	gasLimit, err := evm.EstimateGasLimit(client, interfaces.CallMsg{
+		From:       address,
+		To:         &contractAddress,
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		Value:      payment,
+		Data:       callData,
+		AccessList: accessList,
+	})
+	if err != nil {
+		// Let the tx fail on chain so it can be traced
+		gasLimit = defaultGasLimit
+	}
+	unsignedTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      nonce,
+		To:         &contractAddress,
+		Gas:        gasLimit,
+		GasFeeCap:  gasFeeCap,
+		GasTipCap:  gasTipCap,
+		Value:      payment,
+		Data:       callData,
+		AccessList: accessList,
+	})
+	tx, err := helpers.SignEVMTx(signer, types.LatestSignerForChainID(chainID), unsignedTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := evm.SendTransaction(client, tx); err != nil {
+		return tx, nil, err
+	}
+	return waitForMethodTx(rpcURL, client, tx, description, errorSignatureToError)
+}
+
+func waitForMethodTx(
+	rpcURL string,
+	client ethclient.Client,
+	tx *types.Transaction,
+	description string,
+	errorSignatureToError map[string]error,
+) (*types.Transaction, *types.Receipt, error) {
+	receipt, success, err := evm.WaitForTransaction(client, tx)
+	if err != nil {
+		return tx, receipt, err
+	}
+	if success {
+		return tx, receipt, nil
+	}
+
+	trace, err := contract.DebugTraceTransaction(rpcURL, tx.Hash().String())
+	if err != nil {
+		log.Printf("Could not get debug trace for %s error on %s, tx hash %s: %s\n", description, rpcURL, tx.Hash(), err)
+		return tx, receipt, contract.ErrFailedReceiptStatus
+	}
+	errorFromSignature, err := evm.GetErrorFromTrace(trace, errorSignatureToError)
+	if err != nil && !errors.Is(err, evm.ErrUnknownErrorSelector) {
+		log.Printf("Failed to match error selector on trace: %s\n", err)
+	}
+	if errorFromSignature != nil {
+		return tx, receipt, errorFromSignature
+	}
+	log.Printf("Error trace for %s: %#v\n", description, trace)
+	return tx, receipt, contract.ErrFailedReceiptStatus
+}
+
+// setupProposerVM is evm.SetupProposerVM with a Signer instead of a private
+// key. It issues two transactions so that the chain builds blocks with the
+// ProposerVM, which is required to verify warp messages.
+func setupProposerVM(rpcURL string, signer helpers.Signer) error {
+	const numTriggerTxs = 2
+
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	chainID, err := evm.GetChainID(client)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	address := signer.EthAddress()
+	txSigner := types.LatestSignerForChainID(chainID)
+	for i := 0; i < numTriggerTxs; i++ {
+		prevBlockNumber, err := client.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		nonce, err := client.NonceAt(ctx, address, nil)
+		if err != nil {
+			return err
+		}
+		tx := types.NewTransaction(nonce, address, common.Big1, params.TxGas, big.NewInt(params.MinGasPrice), nil)
+		triggerTx, err := helpers.SignEVMTx(signer, txSigner, tx)
+		if err != nil {
+			return err
+		}
+		if err := client.SendTransaction(ctx, triggerTx); err != nil {
+			return err
+		}
+		if err := evm.WaitForNewBlock(client, ctx, prevBlockNumber, 0, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+const (
+	StagePending  = "pending"
+	StageDone     = "done"
+	StageDiverged = "diverged"
+)
+
+// StageStatus is the state of one pipeline stage as seen locally and on chain
+type StageStatus struct {
+	Stage  string `json:"stage"`
+	State  string `json:"state"`
+	Detail string `json:"detail"`
+}
+
+var statusJSON bool
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the status as JSON")
+	rootCmd.AddCommand(statusCmd)
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Compare the artifacts recorded in the manifest with the P-chain and the L1",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !statusJSON {
+			PrintHeader("🔎 Checking status")
+		}
+
+		manifest, err := helpers.LoadManifest()
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+
+		stages, err := collectStatus(manifest)
+		if err != nil {
+			return err
+		}
+
+		if statusJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(stages)
+		}
+
+		icons := map[string]string{StagePending: "⏳", StageDone: "✅", StageDiverged: "❗"}
+		fmt.Printf("Workspace %s on %s\n\n", helpers.CurrentWorkspace, currentNetwork.Name)
+		for _, stage := range stages {
+			fmt.Printf("%s %-22s %-8s %s\n", icons[stage.State], stage.Stage, stage.State, stage.Detail)
+		}
+		return nil
+	},
+}
+
+func collectStatus(manifest *helpers.Manifest) ([]StageStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pClient := platformvm.NewClient(currentNetwork.PChainURI)
+	managerAddress := common.HexToAddress(config.ProxyContractAddress)
+	stages := []StageStatus{keysStatus()}
+
+	// P-chain
+	if manifest.SubnetID == ids.Empty {
+		stages = append(stages,
+			StageStatus{"subnet", StagePending, "not created"},
+			StageStatus{"chain", StagePending, "not created"},
+			StageStatus{"conversion", StagePending, "not converted"},
+		)
+		return append(stages, l1Status(ctx, manifest, managerAddress, false)...), nil
+	}
+
+	subnet, err := pClient.GetSubnet(ctx, manifest.SubnetID)
+	if err != nil {
+		stages = append(stages, StageStatus{"subnet", StageDiverged, fmt.Sprintf("%s is recorded but the P-chain doesn't know it: %s", manifest.SubnetID, err)})
+	} else {
+		stages = append(stages, StageStatus{"subnet", StageDone, manifest.SubnetID.String()})
+	}
+
+	stages = append(stages, chainStatus(ctx, pClient, manifest))
+
+	switch {
+	case err != nil:
+		stages = append(stages, StageStatus{"conversion", StageDiverged, "subnet is unknown"})
+	case subnet.ConversionID == ids.Empty && manifest.ConversionTxID == ids.Empty:
+		stages = append(stages, StageStatus{"conversion", StagePending, "not converted"})
+	case subnet.ConversionID == ids.Empty:
+		stages = append(stages, StageStatus{"conversion", StageDiverged, fmt.Sprintf("conversion tx %s is recorded but the subnet is not converted", manifest.ConversionTxID)})
+	case manifest.ConversionTxID == ids.Empty:
+		stages = append(stages, StageStatus{"conversion", StageDiverged, fmt.Sprintf("subnet was converted with %s but the manifest has no conversion", subnet.ConversionID)})
+	// Conversions imported from the legacy data files only learn their
+	// conversion ID once initialize-validator-set verifies it
+	case manifest.ConversionID != ids.Empty && subnet.ConversionID != manifest.ConversionID:
+		stages = append(stages, StageStatus{"conversion", StageDiverged, fmt.Sprintf("P-chain has conversion %s, manifest has %s", subnet.ConversionID, manifest.ConversionID)})
+	case subnet.ManagerChainID != manifest.ChainID:
+		stages = append(stages, StageStatus{"conversion", StageDiverged, fmt.Sprintf("manager chain is %s, expected %s", subnet.ManagerChainID, manifest.ChainID)})
+	case !bytes.Equal(subnet.ManagerAddress, managerAddress.Bytes()):
+		stages = append(stages, StageStatus{"conversion", StageDiverged, fmt.Sprintf("manager address is %s, expected %s", common.BytesToAddress(subnet.ManagerAddress), managerAddress)})
+	default:
+		stages = append(stages, StageStatus{"conversion", StageDone, fmt.Sprintf("%s, manager %s", subnet.ConversionID, managerAddress)})
+	}
+
+	if err == nil && subnet.ConversionID != ids.Empty {
+		stages = append(stages, validatorsStatus(manifest))
+	}
+
+	return append(stages, l1Status(ctx, manifest, managerAddress, manifest.ChainID != ids.Empty)...), nil
+}
+
+func keysStatus() StageStatus {
+	ownerKeyExists, err := helpers.OwnerKeyProvider().Exists()
+	if err != nil {
+		return StageStatus{"keys", StageDiverged, err.Error()}
+	}
+	nodeID, _, err := NodeInfoFromCreds(helpers.Node0KeysFolder)
+	switch {
+	case !ownerKeyExists && err != nil:
+		return StageStatus{"keys", StagePending, "not generated"}
+	case !ownerKeyExists:
+		return StageStatus{"keys", StageDiverged, "validator manager owner key is missing"}
+	case err != nil:
+		return StageStatus{"keys", StageDiverged, fmt.Sprintf("node0 credentials are unusable: %s", err)}
+	}
+	return StageStatus{"keys", StageDone, fmt.Sprintf("node0 is %s", nodeID)}
+}
+
+func chainStatus(ctx context.Context, pClient platformvm.Client, manifest *helpers.Manifest) StageStatus {
+	if manifest.ChainID == ids.Empty {
+		return StageStatus{"chain", StagePending, "not created"}
+	}
+	txBytes, err := pClient.GetTx(ctx, manifest.ChainID)
+	if err != nil {
+		return StageStatus{"chain", StageDiverged, fmt.Sprintf("%s is recorded but the P-chain doesn't know it: %s", manifest.ChainID, err)}
+	}
+	tx, err := txs.Parse(txs.Codec, txBytes)
+	if err != nil {
+		return StageStatus{"chain", StageDiverged, fmt.Sprintf("failed to parse tx %s: %s", manifest.ChainID, err)}
+	}
+	createChainTx, ok := tx.Unsigned.(*txs.CreateChainTx)
+	if !ok {
+		return StageStatus{"chain", StageDiverged, fmt.Sprintf("tx %s is a %T, not a CreateChainTx", manifest.ChainID, tx.Unsigned)}
+	}
+	if createChainTx.SubnetID != manifest.SubnetID {
+		return StageStatus{"chain", StageDiverged, fmt.Sprintf("chain %s belongs to subnet %s", manifest.ChainID, createChainTx.SubnetID)}
+	}
+
+	genesisBytes, err := helpers.LoadBytes(helpers.L1GenesisPath)
+	if err == nil && !bytes.Equal(bytes.TrimSpace(genesisBytes), bytes.TrimSpace(createChainTx.GenesisData)) {
+		return StageStatus{"chain", StageDiverged, fmt.Sprintf("%s on disk differs from the genesis chain %s was created with", helpers.L1GenesisPath, manifest.ChainID)}
+	}
+	return StageStatus{"chain", StageDone, manifest.ChainID.String()}
+}
+
+func validatorsStatus(manifest *helpers.Manifest) StageStatus {
+	validatorsResp, err := callPChainValidatorsAt(currentNetwork.PChainEndpoint(), manifest.SubnetID.String())
+	if err != nil {
+		return StageStatus{"validators", StageDiverged, fmt.Sprintf("failed to get validators: %s", err)}
+	}
+
+	active := 0
+	for _, validator := range manifest.Validators {
+		_, onChain := validatorsResp.Validators[validator.NodeID.String()]
+		if validator.Removed && onChain {
+			return StageStatus{"validators", StageDiverged, fmt.Sprintf("%s is recorded as removed but still validates", validator.NodeID)}
+		}
+		if !validator.Removed {
+			active++
+		}
+	}
+	for nodeID := range validatorsResp.Validators {
+		parsed, err := ids.NodeIDFromString(nodeID)
+		if err != nil {
+			return StageStatus{"validators", StageDiverged, fmt.Sprintf("invalid node ID %s: %s", nodeID, err)}
+		}
+		if _, ok := manifest.Validator(parsed); !ok {
+			return StageStatus{"validators", StageDiverged, fmt.Sprintf("%s validates but is not recorded in the manifest", nodeID)}
+		}
+	}
+	return StageStatus{"validators", StageDone, fmt.Sprintf("%d on the P-chain, %d recorded as active", len(validatorsResp.Validators), active)}
+}
+
+// l1Status checks the validator manager contract on node0
+func l1Status(ctx context.Context, manifest *helpers.Manifest, managerAddress common.Address, chainExists bool) []StageStatus {
+	pending := []StageStatus{
+		{"manager-deployed", StagePending, "not deployed"},
+		{"manager-initialized", StagePending, "not initialized"},
+		{"validator-set", StagePending, "not initialized"},
+	}
+	if !chainExists {
+		return pending
+	}
+
+	rpcURL, err := helpers.NodeRPCURL(0, manifest.ChainID)
+	if err != nil {
+		return pending
+	}
+	ethClient, err := ethclient.DialContext(ctx, rpcURL)
+	if err == nil {
+		_, err = ethClient.ChainID(ctx)
+	}
+	if err != nil {
+		detail := fmt.Sprintf("node0 is not reachable at %s", rpcURL)
+		state := StagePending
+		if manifest.InitializeValidatorSetTx != "" {
+			state = StageDiverged
+		}
+		return []StageStatus{
+			{"manager-deployed", state, detail},
+			{"manager-initialized", state, detail},
+			{"validator-set", state, detail},
+		}
+	}
+
+	// The proxy is in the genesis, so the manager is deployed once the
+	// implementation it points at has code
+	stages := []StageStatus{}
+	var code []byte
+	var implementation common.Address
+	implementationSlot, err := ethClient.StorageAt(ctx, managerAddress, eip1967ImplementationSlot, nil)
+	if err == nil {
+		implementation = common.BytesToAddress(implementationSlot)
+		code, err = ethClient.CodeAt(ctx, implementation, nil)
+	}
+	switch {
+	case err != nil:
+		stages = append(stages, StageStatus{"manager-deployed", StageDiverged, fmt.Sprintf("failed to get implementation code: %s", err)})
+	case implementation == (common.Address{}):
+		stages = append(stages, StageStatus{"manager-deployed", StageDiverged, fmt.Sprintf("proxy %s has no implementation", managerAddress)})
+	case len(code) == 0 && manifest.ValidatorType != "":
+		stages = append(stages, StageStatus{"manager-deployed", StageDiverged, fmt.Sprintf("%s manager is recorded but there is no code at implementation %s", manifest.ValidatorType, implementation)})
+	case len(code) == 0:
+		stages = append(stages, StageStatus{"manager-deployed", StagePending, fmt.Sprintf("no code at implementation %s", implementation)})
+	default:
+		detail := fmt.Sprintf("%d bytes at implementation %s", len(code), implementation)
+		if manifest.ValidatorType != "" {
+			detail += ", " + manifest.ValidatorType
+		}
+		stages = append(stages, StageStatus{"manager-deployed", StageDone, detail})
+	}
+
+	contract, err := poavalidatormanager.NewPoAValidatorManager(managerAddress, ethClient)
+	if err != nil {
+		return append(stages, StageStatus{"manager-initialized", StageDiverged, err.Error()})
+	}
+	query := ethereum.FilterQuery{Addresses: []common.Address{managerAddress}}
+	logs, err := ethClient.FilterLogs(ctx, (interfaces.FilterQuery)(query))
+	if err != nil {
+		return append(stages, StageStatus{"manager-initialized", StageDiverged, fmt.Sprintf("failed to get logs: %s", err)})
+	}
+
+	initialized, initialValidators := false, 0
+	for _, vLog := range logs {
+		if _, err := contract.PoAValidatorManagerFilterer.ParseInitialized(vLog); err == nil {
+			initialized = true
+		}
+		if _, err := contract.PoAValidatorManagerFilterer.ParseInitialValidatorCreated(vLog); err == nil {
+			initialValidators++
+		}
+	}
+
+	if initialized {
+		stages = append(stages, StageStatus{"manager-initialized", StageDone, "Initialized event emitted"})
+	} else {
+		stages = append(stages, StageStatus{"manager-initialized", StagePending, "no Initialized event"})
+	}
+
+	switch {
+	case initialValidators > 0 && manifest.InitializeValidatorSetTx != "":
+		stages = append(stages, StageStatus{"validator-set", StageDone, fmt.Sprintf("%d initial validators, tx %s", initialValidators, manifest.InitializeValidatorSetTx)})
+	case initialValidators > 0:
+		stages = append(stages, StageStatus{"validator-set", StageDiverged, fmt.Sprintf("%d initial validators on chain but no tx recorded", initialValidators)})
+	case manifest.InitializeValidatorSetTx != "":
+		stages = append(stages, StageStatus{"validator-set", StageDiverged, fmt.Sprintf("tx %s is recorded but no InitialValidatorCreated event was emitted", manifest.InitializeValidatorSetTx)})
+	default:
+		stages = append(stages, StageStatus{"validator-set", StagePending, "not initialized"})
+	}
+	return stages
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+func TestKeysStatusWithCorruptCertificate(t *testing.T) {
+	inTempDir(t)
+	key, err := secp256k1.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helpers.SaveSecp256k1PrivateKey(helpers.ValidatorManagerOwnerKeyPath, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := generateStakerKey(helpers.Node0KeysFolder); err != nil {
+		t.Fatal(err)
+	}
+	if err := generateSignerKey(helpers.Node0KeysFolder); err != nil {
+		t.Fatal(err)
+	}
+	if status := keysStatus(); status.State != StageDone {
+		t.Fatalf("keys are %s: %s", status.State, status.Detail)
+	}
+
+	for name, cert := range map[string]string{
+		"not PEM":            "not a certificate",
+		"invalid DER in PEM": "-----BEGIN CERTIFICATE-----\nAAECAwQ=\n-----END CERTIFICATE-----\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := helpers.SaveText(helpers.Node0KeysFolder+"staker.crt", cert); err != nil {
+				t.Fatal(err)
+			}
+			status := keysStatus()
+			if status.State != StageDiverged || !strings.Contains(status.Detail, "node0 credentials are unusable") {
+				t.Fatalf("keys are %s: %s", status.State, status.Detail)
+			}
+		})
+	}
+}
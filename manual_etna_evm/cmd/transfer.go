@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	goethereumcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+var transferChains = []string{"C", "P", "X"}
+
+var (
+	transferFrom      string
+	transferTo        string
+	transferAmount    string
+	transferToAddress string
+)
+
+func init() {
+	transferCmd.Flags().StringVar(&transferFrom, "from", "", "Chain to move AVAX from: C, P or X (required)")
+	transferCmd.Flags().StringVar(&transferTo, "to", "", "Chain to move AVAX to: C, P or X (required)")
+	transferCmd.Flags().StringVar(&transferAmount, "amount", "", "AVAX to export, like 1.5 (required)")
+	transferCmd.Flags().StringVar(&transferToAddress, "to-address", "", "Address receiving the AVAX on the destination chain, 0x hex on C and bech32 on P and X (defaults to the fee payer)")
+	transferCmd.MarkFlagRequired("from")
+	transferCmd.MarkFlagRequired("to")
+	transferCmd.MarkFlagRequired("amount")
+	rootCmd.AddCommand(transferCmd)
+}
+
+var transferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Move an exact amount of AVAX between the C, P and X chains",
+	Long: `Move an exact amount of AVAX between the C, P and X chains.
+
+The fee payer exports --amount from the source chain to its own address, then
+imports it to --to-address on the destination chain. The import fee is taken
+from the imported funds. If an earlier transfer was exported but never
+imported, the same command finishes it: when the exported funds are exactly
+--amount they are imported and nothing new is exported. Any other amount is
+refused, so funds of two transfers are never mixed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from := strings.ToUpper(transferFrom)
+		to := strings.ToUpper(transferTo)
+		for _, chain := range []string{from, to} {
+			if !slices.Contains(transferChains, chain) {
+				return fmt.Errorf("unknown chain %q, expected one of %s", chain, strings.Join(transferChains, ", "))
+			}
+		}
+		if from == to {
+			return fmt.Errorf("--from and --to are both %s", from)
+		}
+		amount, err := parseAVAXAmount(transferAmount)
+		if err != nil {
+			return err
+		}
+
+		PrintHeader(fmt.Sprintf("💰 Transferring AVAX from %s-chain to %s-chain", from, to))
+
+		feePayer, err := roleSigner(helpers.RoleFeePayer)
+		if err != nil {
+			return err
+		}
+		toShortAddr, toEthAddr, err := transferDestination(to, feePayer)
+		if err != nil {
+			return err
+		}
+
+		kc := helpers.NewSignerKeychain(feePayer)
+		wallet, err := primary.MakeWallet(context.Background(), &primary.WalletConfig{
+			URI:          currentNetwork.PChainURI,
+			AVAXKeychain: kc,
+			EthKeychain:  kc,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize wallet: %w", err)
+		}
+
+		// Funds exported by an earlier run are still waiting in shared memory
+		pending, err := importableAVAX(wallet, from, to)
+		if err != nil {
+			return err
+		}
+		resume, err := resumeTransfer(pending, amount, from, to)
+		if err != nil {
+			return err
+		}
+		if resume {
+			log.Printf("Found %s AVAX exported from %s-chain but never imported, importing it instead of exporting again\n", formatAVAX(pending), from)
+		} else {
+			owner := &secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{feePayer.Address()},
+			}
+			exportTxID, err := exportAVAX(wallet, from, to, amount, owner)
+			if err != nil {
+				return fmt.Errorf("failed to export from %s-chain: %w", from, err)
+			}
+			log.Printf("✅ Exported %s AVAX from %s-chain in %s\n", formatAVAX(amount), from, exportTxID)
+		}
+
+		importTxID, err := importAVAX(wallet, from, to, toShortAddr, toEthAddr)
+		if err != nil {
+			return fmt.Errorf("failed to import to %s-chain, run the same command again to retry: %w", to, err)
+		}
+		log.Printf("✅ Imported to %s-chain in %s\n", to, importTxID)
+		return nil
+	},
+}
+
+// resumeTransfer tells whether the AVAX pending in shared memory are an
+// earlier export of the requested amount, which only has to be imported.
+// Other pending funds would be swept into the import, so they are refused.
+func resumeTransfer(pending, amount uint64, from, to string) (bool, error) {
+	switch pending {
+	case 0:
+		return false, nil
+	case amount:
+		return true, nil
+	default:
+		return false, fmt.Errorf("%s AVAX were exported from %s-chain but never imported, not the requested %s; run `transfer --from %s --to %s --amount %s` to import them first", formatAVAX(pending), from, formatAVAX(amount), from, to, formatAVAX(pending))
+	}
+}
+
+// transferDestination parses --to-address for chain, or returns the address
+// of the fee payer there
+func transferDestination(chain string, feePayer helpers.Signer) (ids.ShortID, goethereumcommon.Address, error) {
+	if transferToAddress == "" {
+		return feePayer.Address(), feePayer.EthAddress(), nil
+	}
+	if chain == "C" {
+		if !goethereumcommon.IsHexAddress(transferToAddress) {
+			return ids.ShortEmpty, goethereumcommon.Address{}, fmt.Errorf("--to-address must be a 0x hex address on C-chain, got %q", transferToAddress)
+		}
+		return ids.ShortEmpty, goethereumcommon.HexToAddress(transferToAddress), nil
+	}
+
+	chainAlias, hrp, addrBytes, err := address.Parse(transferToAddress)
+	if err != nil {
+		return ids.ShortEmpty, goethereumcommon.Address{}, fmt.Errorf("failed to parse --to-address: %w", err)
+	}
+	if chainAlias != chain {
+		return ids.ShortEmpty, goethereumcommon.Address{}, fmt.Errorf("--to-address %s is a %s-chain address, expected a %s-chain one", transferToAddress, chainAlias, chain)
+	}
+	if expected := avagoconstants.GetHRP(currentNetwork.NetworkID); hrp != expected {
+		return ids.ShortEmpty, goethereumcommon.Address{}, fmt.Errorf("--to-address %s belongs to another network, expected the %s prefix", transferToAddress, expected)
+	}
+	addr, err := ids.ToShortID(addrBytes)
+	if err != nil {
+		return ids.ShortEmpty, goethereumcommon.Address{}, fmt.Errorf("failed to parse --to-address: %w", err)
+	}
+	return addr, goethereumcommon.Address{}, nil
+}
+
+func chainIDOf(wallet primary.Wallet, chain string) ids.ID {
+	switch chain {
+	case "C":
+		return wallet.C().Builder().Context().BlockchainID
+	case "X":
+		return wallet.X().Builder().Context().BlockchainID
+	default:
+		return avagoconstants.PlatformChainID
+	}
+}
+
+func exportAVAX(wallet primary.Wallet, from, to string, amount uint64, owner *secp256k1fx.OutputOwners) (ids.ID, error) {
+	toChainID := chainIDOf(wallet, to)
+	outputs := []*avax.TransferableOutput{{
+		Asset: avax.Asset{ID: wallet.P().Builder().Context().AVAXAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt:          amount,
+			OutputOwners: *owner,
+		},
+	}}
+	switch from {
+	case "C":
+		tx, err := wallet.C().IssueExportTx(toChainID, []*secp256k1fx.TransferOutput{{
+			Amt:          amount,
+			OutputOwners: *owner,
+		}})
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	case "X":
+		tx, err := wallet.X().IssueExportTx(toChainID, outputs)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	default:
+		tx, err := wallet.P().IssueExportTx(toChainID, outputs)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	}
+}
+
+// importableAVAX is what was exported from one chain to the other and can be
+// imported by the wallet
+func importableAVAX(wallet primary.Wallet, from, to string) (uint64, error) {
+	fromChainID := chainIDOf(wallet, from)
+	avaxAssetID := wallet.P().Builder().Context().AVAXAssetID
+	var (
+		balance uint64
+		err     error
+	)
+	switch to {
+	case "C":
+		balance, err = wallet.C().Builder().GetImportableBalance(fromChainID)
+	case "X":
+		var balances map[ids.ID]uint64
+		balances, err = wallet.X().Builder().GetImportableBalance(fromChainID)
+		balance = balances[avaxAssetID]
+	default:
+		var balances map[ids.ID]uint64
+		balances, err = wallet.P().Builder().GetImportableBalance(fromChainID)
+		balance = balances[avaxAssetID]
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get AVAX importable from %s-chain to %s-chain: %w", from, to, err)
+	}
+	return balance, nil
+}
+
+func importAVAX(wallet primary.Wallet, from, to string, toShortAddr ids.ShortID, toEthAddr goethereumcommon.Address) (ids.ID, error) {
+	fromChainID := chainIDOf(wallet, from)
+	owner := &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{toShortAddr},
+	}
+	switch to {
+	case "C":
+		tx, err := wallet.C().IssueImportTx(fromChainID, toEthAddr)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	case "X":
+		tx, err := wallet.X().IssueImportTx(fromChainID, owner)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	default:
+		tx, err := wallet.P().IssueImportTx(fromChainID, owner)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	}
+}
+
+// parseAVAXAmount parses a decimal AVAX amount like 1.5 into nAVAX
+func parseAVAXAmount(amount string) (uint64, error) {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(amount), ".")
+	if len(fraction) > 9 {
+		return 0, fmt.Errorf("amount %q has more than 9 decimals", amount)
+	}
+	wholeAVAX, err := strconv.ParseUint(whole, 10, 64)
+	if whole == "" {
+		wholeAVAX, err = 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+	nAVAX := uint64(0)
+	if fraction != "" {
+		nAVAX, err = strconv.ParseUint(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q: %w", amount, err)
+		}
+	}
+	if wholeAVAX > (^uint64(0)-nAVAX)/units.Avax {
+		return 0, fmt.Errorf("amount %q is too large", amount)
+	}
+	total := wholeAVAX*units.Avax + nAVAX
+	if total == 0 {
+		return 0, fmt.Errorf("amount must be more than 0")
+	}
+	return total, nil
+}
+
+func formatAVAX(nAVAX uint64) string {
+	return GetBalanceString(new(big.Int).SetUint64(nAVAX), 9)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+func TestParseAVAXAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   uint64
+	}{
+		{"1", units.Avax},
+		{"1.5", 1500 * units.MilliAvax},
+		{" 2.25 ", 2250 * units.MilliAvax},
+		{".5", 500 * units.MilliAvax},
+		{"0.000000001", 1},
+		{"1.000000001", units.Avax + 1},
+		{"18446744073.709551615", ^uint64(0)},
+	}
+	for _, test := range tests {
+		got, err := parseAVAXAmount(test.amount)
+		if err != nil {
+			t.Fatalf("parseAVAXAmount(%q): %s", test.amount, err)
+		}
+		if got != test.want {
+			t.Fatalf("parseAVAXAmount(%q) = %d, want %d", test.amount, got, test.want)
+		}
+	}
+
+	for _, amount := range []string{"", "0", "0.0", "1.0000000001", "-1", "1.-5", "abc", "1,5", "18446744073.709551616", "18446744074"} {
+		if got, err := parseAVAXAmount(amount); err == nil {
+			t.Fatalf("parseAVAXAmount(%q) = %d, want an error", amount, got)
+		}
+	}
+}
+
+func TestFormatAVAX(t *testing.T) {
+	tests := []struct {
+		nAVAX uint64
+		want  string
+	}{
+		{0, "0.000000000"},
+		{1, "0.000000001"},
+		{1500 * units.MilliAvax, "1.500000000"},
+		{^uint64(0), "18446744073.709551615"},
+	}
+	for _, test := range tests {
+		if got := formatAVAX(test.nAVAX); got != test.want {
+			t.Fatalf("formatAVAX(%d) = %q, want %q", test.nAVAX, got, test.want)
+		}
+		if test.nAVAX == 0 {
+			continue
+		}
+		parsed, err := parseAVAXAmount(formatAVAX(test.nAVAX))
+		if err != nil || parsed != test.nAVAX {
+			t.Fatalf("parseAVAXAmount(formatAVAX(%d)) = %d, %v", test.nAVAX, parsed, err)
+		}
+	}
+}
+
+func TestResumeTransfer(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending uint64
+		resume  bool
+		fails   bool
+	}{
+		{"nothing pending", 0, false, false},
+		{"same amount pending", 2 * units.Avax, true, false},
+		{"less pending", units.Avax, false, true},
+		{"more pending", 3 * units.Avax, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resume, err := resumeTransfer(test.pending, 2*units.Avax, "C", "P")
+			if (err != nil) != test.fails {
+				t.Fatalf("resumeTransfer(%d) error = %v, want failure %t", test.pending, err, test.fails)
+			}
+			if resume != test.resume {
+				t.Fatalf("resumeTransfer(%d) = %t, want %t", test.pending, resume, test.resume)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+// pipelineStep is one node of the step graph `up` walks through. outputs
+// reads back what the step produced so that steps depending on it can tell
+// when they ran against something that has since changed. existing describes
+// what the step made that can't be made again in this workspace, like a tx
+// committed on the P-chain.
+type pipelineStep struct {
+	name      string
+	cmd       *cobra.Command
+	dependsOn []string
+	outputs   func(manifest *helpers.Manifest) (map[string]string, error)
+	existing  func(manifest *helpers.Manifest) string
+}
+
+// pipelineSteps are listed in the order they run; every step only depends on steps listed before it
+var pipelineSteps = []pipelineStep{
+	{name: "generate-keys", cmd: GenerateKeysCmd, outputs: keysOutputs},
+	{name: "transfer-coins", cmd: TransferCoinsCmd, dependsOn: []string{"generate-keys"}},
+	{name: "create-subnet", cmd: CreateSubnetCmd, dependsOn: []string{"transfer-coins"}, outputs: func(m *helpers.Manifest) (map[string]string, error) {
+		if m.SubnetID == ids.Empty {
+			return nil, errors.New("no subnet ID was recorded")
+		}
+		return map[string]string{"subnetID": m.SubnetID.String()}, nil
+	}, existing: func(m *helpers.Manifest) string {
+		if m.SubnetID == ids.Empty {
+			return ""
+		}
+		return fmt.Sprintf("subnet %s already exists on the P-chain", m.SubnetID)
+	}},
+	{name: "generate-genesis", cmd: GenerateGenesisCmd, dependsOn: []string{"generate-keys"}, outputs: genesisOutputs, existing: func(m *helpers.Manifest) string {
+		if m.ChainID == ids.Empty {
+			return ""
+		}
+		return fmt.Sprintf("chain %s was already created from %s", m.ChainID, helpers.L1GenesisPath)
+	}},
+	{name: "create-chain", cmd: CreateChainCmd, dependsOn: []string{"create-subnet", "generate-genesis"}, outputs: func(m *helpers.Manifest) (map[string]string, error) {
+		if m.ChainID == ids.Empty {
+			return nil, errors.New("no chain ID was recorded")
+		}
+		return map[string]string{"chainID": m.ChainID.String()}, nil
+	}, existing: func(m *helpers.Manifest) string {
+		if m.ChainID == ids.Empty {
+			return ""
+		}
+		return fmt.Sprintf("chain %s already exists on the P-chain", m.ChainID)
+	}},
+	{name: "convert-to-L1", cmd: ConvertToL1Cmd, dependsOn: []string{"create-chain"}, outputs: func(m *helpers.Manifest) (map[string]string, error) {
+		if m.ConversionTxID == ids.Empty {
+			return nil, errors.New("no conversion tx was recorded")
+		}
+		return map[string]string{"conversionTxID": m.ConversionTxID.String(), "conversionID": m.ConversionID.String(), "managerAddress": m.ManagerAddress.Hex()}, nil
+	}, existing: func(m *helpers.Manifest) string {
+		if m.ConversionTxID == ids.Empty {
+			return ""
+		}
+		return fmt.Sprintf("the subnet was already converted by tx %s", m.ConversionTxID)
+	}},
+	{name: "launch-node", cmd: launchNodeCmd, dependsOn: []string{"convert-to-L1"}, outputs: func(m *helpers.Manifest) (map[string]string, error) {
+		uri, err := helpers.NodeURI(0)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"container": helpers.ContainerName(0), "uri": uri}, nil
+	}},
+	{name: "deploy", cmd: deployValidatorManagerCmd, dependsOn: []string{"launch-node"}, outputs: func(m *helpers.Manifest) (map[string]string, error) {
+		return map[string]string{"validatorType": m.ValidatorType}, nil
+	}},
+	{name: "init", cmd: validatorManagerInitCmd, dependsOn: []string{"deploy"}},
+	{name: "initialize-validator-set", cmd: initializeValidatorSetCmd, dependsOn: []string{"init"}, outputs: func(m *helpers.Manifest) (map[string]string, error) {
+		if m.InitializeValidatorSetTx == "" {
+			return nil, errors.New("no initialize validator set transaction was recorded")
+		}
+		return map[string]string{"tx": m.InitializeValidatorSetTx}, nil
+	}},
+}
+
+var (
+	upFrom             string
+	upUntil            string
+	upOnly             string
+	upValidatorType    string
+	upManagerInGenesis bool
+)
+
+func init() {
+	upCmd.Flags().StringVar(&upFrom, "from", "", "Rerun this step and every step after it")
+	upCmd.Flags().StringVar(&upUntil, "until", "", "Stop after this step")
+	upCmd.Flags().StringVar(&upOnly, "only", "", "Rerun just this step, its dependencies must be done")
+	upCmd.Flags().StringVar(&upValidatorType, "validator-type", config.PoAMode, fmt.Sprintf("Type of validator manager to deploy (%s or %s)", config.PoAMode, config.PoSNativeMode))
+	upCmd.Flags().BoolVar(&upManagerInGenesis, "manager-in-genesis", false, "Embed the validator manager implementation in the genesis instead of deploying it after launch")
+	upCmd.Flags().StringArrayVar(&convertBootstrapValidators, "bootstrap-validator", nil, "Bootstrap validator for convert-to-L1, see convert-to-L1 --help (default node0)")
+	rootCmd.AddCommand(upCmd)
+}
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Run the whole L1 setup, resuming from the first incomplete step",
+	Long: fmt.Sprintf(`Run the whole L1 setup, resuming from the first incomplete step.
+
+Steps: %s
+
+A step is done when its last run succeeded against the current outputs of
+the steps it depends on. Rerunning a step whose outputs change makes every
+step depending on it run again.`, strings.Join(pipelineStepNames(), ", ")),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if upOnly != "" && (upFrom != "" || upUntil != "") {
+			return errors.New("--only can't be combined with --from or --until")
+		}
+
+		manifest, err := helpers.LoadManifest()
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		if manifest.ValidatorType != "" && manifest.ValidatorType != upValidatorType {
+			if cmd.Flags().Changed("validator-type") {
+				return fmt.Errorf("validator manager was already deployed as %s, can't switch to %s", manifest.ValidatorType, upValidatorType)
+			}
+			upValidatorType = manifest.ValidatorType
+		}
+		validatorType = upValidatorType
+		if manifest.ManagerInGenesis && !cmd.Flags().Changed("manager-in-genesis") {
+			upManagerInGenesis = true
+		}
+		if upManagerInGenesis && !manifest.ManagerInGenesis {
+			if manifest.ChainID != ids.Empty {
+				return fmt.Errorf("chain %s was created from a genesis without the validator manager, --manager-in-genesis needs a new chain: start over in a new workspace with `workspace create <name>`", manifest.ChainID)
+			}
+			if manifest.Step("generate-genesis").Status == helpers.StepDone && upFrom == "" && upOnly == "" {
+				return errors.New("the genesis was generated without the validator manager, run `up --from generate-genesis --manager-in-genesis` to regenerate it")
+			}
+		}
+		managerInGenesis = upManagerInGenesis
+
+		first, last := 0, len(pipelineSteps)-1
+		forced := map[string]bool{}
+		if upOnly != "" {
+			if first, err = pipelineStepIndex(upOnly); err != nil {
+				return err
+			}
+			last = first
+			forced[upOnly] = true
+		}
+		if upFrom != "" {
+			if first, err = pipelineStepIndex(upFrom); err != nil {
+				return err
+			}
+			for _, step := range pipelineSteps[first:] {
+				forced[step.name] = true
+			}
+		}
+		if upUntil != "" {
+			if last, err = pipelineStepIndex(upUntil); err != nil {
+				return err
+			}
+		}
+		if first > last {
+			return fmt.Errorf("step %s comes after %s", pipelineSteps[first].name, pipelineSteps[last].name)
+		}
+
+		if err := checkUpFunds(first, last); err != nil {
+			return err
+		}
+
+		for _, step := range pipelineSteps[first : last+1] {
+			if err := runPipelineStep(step, forced[step.name]); err != nil {
+				return err
+			}
+		}
+
+		log.Printf("✅ Steps %s to %s are done\n", pipelineSteps[first].name, pipelineSteps[last].name)
+		return nil
+	},
+}
+
+func runPipelineStep(step pipelineStep, force bool) error {
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	for _, dep := range step.dependsOn {
+		upToDate, err := pipelineStepUpToDate(manifest, dep)
+		if err != nil {
+			return err
+		}
+		if !upToDate {
+			return fmt.Errorf("step %s depends on %s, which is %s or out of date; run `up --from %s`", step.name, dep, manifest.Step(dep).Status, dep)
+		}
+	}
+
+	upToDate, err := pipelineStepUpToDate(manifest, step.name)
+	if err != nil {
+		return err
+	}
+	if upToDate && !force {
+		log.Printf("⏭️  Step %s is already done, skipping\n", step.name)
+		return nil
+	}
+
+	inputs, err := pipelineStepInputs(manifest, step)
+	if err != nil {
+		return err
+	}
+
+	// A step that can't be redone is never marked done against inputs it
+	// didn't run with. What it made before up tracked it, or before a run
+	// that failed afterwards, is taken over as is.
+	adopt := false
+	if step.existing != nil {
+		if existing := step.existing(manifest); existing != "" {
+			if force || manifest.Step(step.name).Status == helpers.StepDone {
+				return fmt.Errorf("step %s can't be redone: %s. Start over in a new workspace with `workspace create <name>`, or tear this one down with `down`", step.name, existing)
+			}
+			log.Printf("Step %s: %s, recording it as done\n", step.name, existing)
+			adopt = true
+		}
+	}
+
+	// Outputs are cleared while the step runs, so a step that fails halfway
+	// never leaves the steps after it looking up to date
+	startedAt := time.Now().UTC()
+	if err := setPipelineStep(step.name, helpers.StepStatus{Status: helpers.StepRunning, StartedAt: startedAt, Inputs: inputs}); err != nil {
+		return err
+	}
+
+	var stepErr error
+	if !adopt {
+		stepErr = step.cmd.RunE(step.cmd, nil)
+	}
+
+	var outputs map[string]string
+	if stepErr == nil && step.outputs != nil {
+		manifest, err = helpers.LoadManifest()
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		outputs, stepErr = step.outputs(manifest)
+	}
+
+	status := helpers.StepStatus{
+		Status:     helpers.StepDone,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now().UTC(),
+		Inputs:     inputs,
+		Outputs:    outputs,
+	}
+	if stepErr != nil {
+		status.Status = helpers.StepFailed
+		status.Error = stepErr.Error()
+	}
+	if err := setPipelineStep(step.name, status); err != nil {
+		return err
+	}
+	if stepErr != nil {
+		return fmt.Errorf("step %s failed: %w", step.name, stepErr)
+	}
+	return nil
+}
+
+func setPipelineStep(name string, status helpers.StepStatus) error {
+	err := helpers.UpdateManifest(func(manifest *helpers.Manifest) error {
+		manifest.SetStep(name, status)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record status of step %s: %w", name, err)
+	}
+	return nil
+}
+
+// pipelineStepUpToDate reports whether a step is done and the steps it
+// depends on haven't produced different outputs since it ran
+func pipelineStepUpToDate(manifest *helpers.Manifest, name string) (bool, error) {
+	status := manifest.Step(name)
+	if status.Status != helpers.StepDone {
+		return false, nil
+	}
+	index, err := pipelineStepIndex(name)
+	if err != nil {
+		return false, err
+	}
+	step := pipelineSteps[index]
+	for _, dep := range step.dependsOn {
+		upToDate, err := pipelineStepUpToDate(manifest, dep)
+		if err != nil || !upToDate {
+			return false, err
+		}
+	}
+	inputs, err := pipelineStepInputs(manifest, step)
+	if err != nil {
+		return false, err
+	}
+	return status.Inputs == inputs, nil
+}
+
+// pipelineStepInputs digests the recorded outputs of the steps a step depends on
+func pipelineStepInputs(manifest *helpers.Manifest, step pipelineStep) (string, error) {
+	deps := append([]string{}, step.dependsOn...)
+	sort.Strings(deps)
+	outputs := map[string]map[string]string{}
+	for _, dep := range deps {
+		outputs[dep] = manifest.Step(dep).Outputs
+	}
+	outputsBytes, err := json.Marshal(outputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal outputs: %w", err)
+	}
+	digest := sha256.Sum256(outputsBytes)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+func pipelineStepIndex(name string) (int, error) {
+	for i, step := range pipelineSteps {
+		if step.name == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown step %q, must be one of: %s", name, strings.Join(pipelineStepNames(), ", "))
+}
+
+func pipelineStepNames() []string {
+	names := make([]string, len(pipelineSteps))
+	for i, step := range pipelineSteps {
+		names[i] = step.name
+	}
+	return names
+}
+
+func keysOutputs(manifest *helpers.Manifest) (map[string]string, error) {
+	owner, err := ownerAccount()
+	if err != nil {
+		return nil, err
+	}
+	nodeID, _, err := NodeInfoFromCreds(helpers.Node0KeysFolder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load node0 credentials: %w", err)
+	}
+	outputs := map[string]string{
+		"owner":  owner.EthAddress.Hex(),
+		"node0":  nodeID.String(),
+		"pChain": owner.Address.String(),
+	}
+	// Handing a role to someone else reruns the steps that depend on it
+	for _, role := range helpers.Roles {
+		addr, err := roleAddressString(role)
+		if err != nil {
+			return nil, err
+		}
+		outputs[role.Name] = addr
+	}
+	return outputs, nil
+}
+
+func genesisOutputs(manifest *helpers.Manifest) (map[string]string, error) {
+	genesisBytes, err := helpers.LoadBytes(helpers.L1GenesisPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load genesis: %w", err)
+	}
+	digest := sha256.Sum256(genesisBytes)
+	return map[string]string{"sha256": hex.EncodeToString(digest[:])}, nil
+}
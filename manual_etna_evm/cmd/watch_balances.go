@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval      time.Duration
+	watchThresholdDays float64
+	watchTopUp         string
+	watchSpendCap      string
+	watchAlertWebhook  string
+	watchOnce          bool
+)
+
+func init() {
+	watchBalancesCmd.Flags().DurationVar(&watchInterval, "interval", 10*time.Minute, "Time between two checks")
+	watchBalancesCmd.Flags().Float64Var(&watchThresholdDays, "threshold-days", 7, "Alert when a validator has fewer days of runway left")
+	watchBalancesCmd.Flags().StringVar(&watchTopUp, "top-up", "", "AVAX the fee payer adds to a validator below the threshold, like 1.5 (disabled by default)")
+	watchBalancesCmd.Flags().StringVar(&watchSpendCap, "spend-cap", "", "Most AVAX --top-up may add in total while the command runs (required with --top-up)")
+	watchBalancesCmd.Flags().StringVar(&watchAlertWebhook, "alert-webhook", "", "URL alerts are POSTed to as {\"text\": ...}, like a Slack incoming webhook")
+	watchBalancesCmd.Flags().BoolVar(&watchOnce, "once", false, "Check once and exit, for cron jobs")
+	rootCmd.AddCommand(watchBalancesCmd)
+}
+
+var watchBalancesCmd = &cobra.Command{
+	Use:   "watch-balances [NodeID|ValidationID...]",
+	Short: "Watch the continuous fee runway of L1 validators and top them up",
+	Long: `Watch the continuous fee runway of L1 validators and top them up.
+
+Every --interval the balance of each validator is fetched from the P-chain
+and divided by the continuous fee rate to get its runway. The rate is
+measured from how fast the balances went down since the last check, which is
+kept in the workspace so --once runs measure it too. Until a rate was
+measured, the runway is estimated at the minimum price of the network and is
+labelled as such, the actual runway may be shorter. Validators with less than
+--threshold-days left, or already inactive, raise an alert in the log and on
+--alert-webhook.
+
+With --top-up the fee payer adds that much AVAX to each such validator, until
+the top-ups reach --spend-cap. The validators default to the ones of the
+manifest that weren't removed or disabled.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		watcher, err := newBalanceWatcher(args)
+		if err != nil {
+			return err
+		}
+
+		PrintHeader("👀 Watching L1 validator balances")
+		for {
+			if err := watcher.check(context.Background()); err != nil {
+				if watchOnce {
+					return err
+				}
+				log.Printf("❌ %s\n", err)
+			}
+			if watchOnce {
+				return nil
+			}
+			time.Sleep(watchInterval)
+		}
+	},
+}
+
+type balanceWatcher struct {
+	pClient       platformvm.Client
+	validationIDs []ids.ID
+	threshold     time.Duration
+
+	// kc pays the top-ups, it is nil without --top-up
+	kc       keychain.Keychain
+	topUp    uint64
+	spendCap uint64
+	spent    uint64
+
+	// feeRate is the continuous fee in nAVAX per second of each validator,
+	// the minimum price until it was measured from the drop since last
+	feeRate  uint64
+	measured bool
+	last     map[ids.ID]helpers.BalanceSample
+}
+
+func newBalanceWatcher(args []string) (*balanceWatcher, error) {
+	if watchInterval <= 0 {
+		return nil, fmt.Errorf("--interval must be positive, got %s", watchInterval)
+	}
+	if watchThresholdDays < 0 {
+		return nil, fmt.Errorf("--threshold-days can't be negative, got %g", watchThresholdDays)
+	}
+	validationIDs, err := watchedValidationIDs(args)
+	if err != nil {
+		return nil, err
+	}
+	last, err := helpers.LoadBalanceSamples()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balance samples: %w", err)
+	}
+
+	watcher := &balanceWatcher{
+		pClient:       platformvm.NewClient(currentNetwork.PChainURI),
+		validationIDs: validationIDs,
+		threshold:     time.Duration(watchThresholdDays * float64(24*time.Hour)),
+		feeRate:       uint64(genesis.GetTxFeeConfig(currentNetwork.NetworkID).ValidatorFeeConfig.MinPrice),
+		last:          last,
+	}
+
+	if watchTopUp == "" {
+		if watchSpendCap != "" {
+			return nil, fmt.Errorf("--spend-cap needs --top-up")
+		}
+		return watcher, nil
+	}
+	if watchSpendCap == "" {
+		return nil, fmt.Errorf("--top-up needs --spend-cap")
+	}
+	watcher.topUp, err = parseAVAXAmount(watchTopUp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --top-up: %w", err)
+	}
+	watcher.spendCap, err = parseAVAXAmount(watchSpendCap)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --spend-cap: %w", err)
+	}
+	if watcher.topUp > watcher.spendCap {
+		return nil, fmt.Errorf("--top-up %s AVAX is more than --spend-cap %s AVAX, no top-up could ever be made", formatAVAX(watcher.topUp), formatAVAX(watcher.spendCap))
+	}
+	// Check the fee payer key now rather than at the first top-up
+	watcher.kc, _, err = pChainKeychain(helpers.RoleFeePayer)
+	if err != nil {
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// watchedValidationIDs resolves args, or lists the validators of the manifest
+func watchedValidationIDs(args []string) ([]ids.ID, error) {
+	validationIDs := []ids.ID{}
+	for _, arg := range args {
+		validationID, err := resolveValidationID(arg)
+		if err != nil {
+			return nil, err
+		}
+		validationIDs = append(validationIDs, validationID)
+	}
+	if len(args) > 0 {
+		return validationIDs, nil
+	}
+
+	manifest, err := helpers.LoadManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	for _, validator := range manifest.Validators {
+		if !validator.Removed && !validator.Disabled && validator.ValidationID != ids.Empty {
+			validationIDs = append(validationIDs, validator.ValidationID)
+		}
+	}
+	if len(validationIDs) == 0 {
+		return nil, fmt.Errorf("the manifest has no validators, pass NodeIDs or validation IDs to watch")
+	}
+	return validationIDs, nil
+}
+
+func (w *balanceWatcher) check(ctx context.Context) error {
+	chainTime, err := w.pClient.GetTimestamp(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get P-chain time: %w", err)
+	}
+	validators := make([]platformvm.L1Validator, len(w.validationIDs))
+	for i, validationID := range w.validationIDs {
+		validators[i], _, err = w.pClient.GetL1Validator(ctx, validationID)
+		if err != nil {
+			return fmt.Errorf("failed to get L1 validator %s: %w", validationID, err)
+		}
+	}
+	w.measureFeeRate(validators, chainTime)
+
+	if w.measured {
+		log.Printf("P-chain time %s, measured continuous fee %s AVAX per day\n", chainTime.UTC().Format(time.RFC3339), formatAVAX(w.feeRate*uint64(24*time.Hour/time.Second)))
+	} else {
+		log.Printf("P-chain time %s, no continuous fee measured yet, estimating runways at the minimum price of %s AVAX per day\n", chainTime.UTC().Format(time.RFC3339), formatAVAX(w.feeRate*uint64(24*time.Hour/time.Second)))
+	}
+
+	for i, validator := range validators {
+		validationID := w.validationIDs[i]
+		if validator.Balance == 0 {
+			w.alert(fmt.Sprintf("Validator %s of subnet %s has no balance left and is inactive", validator.NodeID, validator.SubnetID))
+			w.topUpValidator(validationID, validator.NodeID)
+			continue
+		}
+
+		runway := time.Duration(validator.Balance/max(w.feeRate, 1)) * time.Second
+		days := fmt.Sprintf("%.1f days", runway.Hours()/24)
+		if !w.measured {
+			days = "at most " + days + " (minimum-price estimate)"
+		}
+		if runway >= w.threshold {
+			log.Printf("✅ %s: %s AVAX, %s left\n", validator.NodeID, formatAVAX(validator.Balance), days)
+			continue
+		}
+		w.alert(fmt.Sprintf("Validator %s of subnet %s has %s AVAX left, %s of runway", validator.NodeID, validator.SubnetID, formatAVAX(validator.Balance), days))
+		w.topUpValidator(validationID, validator.NodeID)
+	}
+
+	if err := helpers.SaveBalanceSamples(w.last); err != nil {
+		return fmt.Errorf("failed to save balance samples: %w", err)
+	}
+	return nil
+}
+
+// measureFeeRate updates the fee rate from the balances that went down since
+// the last check. Every active validator pays the same rate, so the fastest
+// drop is the most recent measure.
+func (w *balanceWatcher) measureFeeRate(validators []platformvm.L1Validator, chainTime time.Time) {
+	rate := uint64(0)
+	for i, validator := range validators {
+		validationID := w.validationIDs[i]
+		last, ok := w.last[validationID]
+		w.last[validationID] = helpers.BalanceSample{Balance: validator.Balance, ChainTime: chainTime}
+
+		// A balance going up was topped up, one at zero stopped paying
+		if !ok || !chainTime.After(last.ChainTime) || validator.Balance == 0 || validator.Balance >= last.Balance {
+			continue
+		}
+		seconds := uint64(chainTime.Sub(last.ChainTime) / time.Second)
+		if seconds == 0 {
+			continue
+		}
+		rate = max(rate, (last.Balance-validator.Balance)/seconds)
+	}
+	if rate > 0 {
+		w.feeRate = rate
+		w.measured = true
+	}
+}
+
+func (w *balanceWatcher) topUpValidator(validationID ids.ID, nodeID ids.NodeID) {
+	if w.topUp == 0 {
+		return
+	}
+	if w.spent+w.topUp > w.spendCap {
+		w.alert(fmt.Sprintf("Not topping up %s, the top-ups already added %s of the %s AVAX spend cap", nodeID, formatAVAX(w.spent), formatAVAX(w.spendCap)))
+		return
+	}
+	if err := checkIncreaseBalanceFunds(w.topUp); err != nil {
+		w.alert(fmt.Sprintf("Failed to top up %s: %s", nodeID, err))
+		return
+	}
+
+	wallet, err := pChainWallet(w.kc, nil, nil)
+	if err != nil {
+		w.alert(fmt.Sprintf("Failed to top up %s: %s", nodeID, err))
+		return
+	}
+	unsignedTx, err := wallet.Builder().NewIncreaseL1ValidatorBalanceTx(validationID, w.topUp)
+	if err != nil {
+		w.alert(fmt.Sprintf("Failed to top up %s: failed to build increase balance tx: %s", nodeID, err))
+		return
+	}
+	tx, err := issuePChainTx(wallet, unsignedTx)
+	if err != nil {
+		w.alert(fmt.Sprintf("Failed to top up %s: %s", nodeID, err))
+		return
+	}
+	w.spent += w.topUp
+	// Keep the top-up out of the next fee rate measure
+	if sample, ok := w.last[validationID]; ok {
+		sample.Balance += w.topUp
+		w.last[validationID] = sample
+	}
+	log.Printf("✅ Topped up %s with %s AVAX in tx %s, %s of the %s AVAX spend cap used\n", nodeID, formatAVAX(w.topUp), tx.ID(), formatAVAX(w.spent), formatAVAX(w.spendCap))
+}
+
+// alert logs message and posts it to --alert-webhook
+func (w *balanceWatcher) alert(message string) {
+	log.Printf("⚠️  %s\n", message)
+	if watchAlertWebhook == "" {
+		return
+	}
+	if err := postAlert(watchAlertWebhook, message); err != nil {
+		log.Printf("❌ Failed to post alert: %s\n", err)
+	}
+}
+
+func postAlert(url string, message string) error {
+	payload, err := json.Marshal(map[string]string{"text": message})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+)
+
+func TestBalanceWatcherRejectsTopUpOverSpendCap(t *testing.T) {
+	inTempDir(t)
+	t.Cleanup(func() { watchTopUp, watchSpendCap = "", "" })
+	watchTopUp, watchSpendCap = "2", "1.5"
+
+	_, err := newBalanceWatcher([]string{ids.GenerateTestID().String()})
+	if err == nil || !strings.Contains(err.Error(), "more than --spend-cap") {
+		t.Fatalf("got %v, want a --top-up over --spend-cap error", err)
+	}
+}
+
+func TestMeasureFeeRateAcrossRuns(t *testing.T) {
+	inTempDir(t)
+	validationIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+	start := time.Unix(1_700_000_000, 0).UTC()
+	const minPrice = 512
+
+	// Each --once run starts a new watcher from the saved samples
+	check := func(chainTime time.Time, balances ...uint64) *balanceWatcher {
+		t.Helper()
+		last, err := helpers.LoadBalanceSamples()
+		if err != nil {
+			t.Fatal(err)
+		}
+		watcher := &balanceWatcher{validationIDs: validationIDs, feeRate: minPrice, last: last}
+		validators := make([]platformvm.L1Validator, len(balances))
+		for i, balance := range balances {
+			validators[i].Balance = balance
+		}
+		watcher.measureFeeRate(validators, chainTime)
+		if err := helpers.SaveBalanceSamples(watcher.last); err != nil {
+			t.Fatal(err)
+		}
+		return watcher
+	}
+
+	first := check(start, 10*units.Avax, 5*units.Avax)
+	if first.measured || first.feeRate != minPrice {
+		t.Fatalf("first run measured %d nAVAX/s, want the minimum price estimate", first.feeRate)
+	}
+
+	// The second validator was topped up, only the first one measures
+	second := check(start.Add(time.Hour), 10*units.Avax-3600*1000, 6*units.Avax)
+	if !second.measured || second.feeRate != 1000 {
+		t.Fatalf("second run measured %d nAVAX/s (measured %t), want 1000", second.feeRate, second.measured)
+	}
+
+	// The same chain time again has nothing to measure
+	third := check(start.Add(time.Hour), 10*units.Avax-3600*1000, 6*units.Avax)
+	if third.measured {
+		t.Fatalf("third run measured %d nAVAX/s without time passing", third.feeRate)
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/helpers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workspaceName         string
+	workspaceHTTPPort     int
+	workspaceDeleteForced bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&workspaceName, "workspace", "", fmt.Sprintf("Workspace to use (defaults to $%s, then the one selected with \"workspace switch\")", helpers.WorkspaceEnvVar))
+
+	workspaceCreateCmd.Flags().IntVar(&workspaceHTTPPort, "http-port", 0, "HTTP port of node0, further nodes use the ports after it (picks a free range by default)")
+	workspaceDeleteCmd.Flags().BoolVar(&workspaceDeleteForced, "force", false, "Delete the workspace even if it holds keys or a subnet")
+
+	workspaceCmd.AddCommand(workspaceListCmd, workspaceCreateCmd, workspaceSwitchCmd, workspaceDeleteCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
+
+// loadWorkspace resolves the workspace from the flag, the environment or the
+// active workspace file, in that order
+func loadWorkspace() error {
+	name := workspaceName
+	if name == "" {
+		name = os.Getenv(helpers.WorkspaceEnvVar)
+	}
+	if name == "" {
+		active, err := helpers.ActiveWorkspace()
+		if err != nil {
+			return fmt.Errorf("failed to load active workspace: %w", err)
+		}
+		name = active
+	}
+
+	exists, err := helpers.WorkspaceExists(name)
+	if err != nil {
+		return fmt.Errorf("failed to check if workspace %s exists: %w", name, err)
+	}
+	if !exists {
+		return fmt.Errorf("workspace %s does not exist, create it with `workspace create %s`", name, name)
+	}
+
+	return helpers.UseWorkspace(name)
+}
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage workspaces, each holding the state of one L1",
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:         "list",
+	Annotations: offline,
+	Short:       "List workspaces",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := helpers.ListWorkspaces()
+		if err != nil {
+			return fmt.Errorf("failed to list workspaces: %w", err)
+		}
+
+		for _, name := range names {
+			manifest, err := helpers.LoadWorkspaceManifest(name)
+			if err != nil {
+				return fmt.Errorf("failed to load manifest of workspace %s: %w", name, err)
+			}
+
+			marker := " "
+			if name == helpers.CurrentWorkspace {
+				marker = "*"
+			}
+			subnet := "no subnet"
+			if manifest.SubnetID != ids.Empty {
+				subnet = "subnet " + manifest.SubnetID.String()
+			}
+			fmt.Printf("%s %-20s port %d  %s  (%s)\n", marker, name, manifest.NodeBaseHTTPPort(), subnet, helpers.WorkspaceDataDir(name))
+		}
+		return nil
+	},
+}
+
+var workspaceCreateCmd = &cobra.Command{
+	Use:         "create <name>",
+	Annotations: offline,
+	Short:       "Create a workspace",
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := helpers.CreateWorkspace(name, workspaceHTTPPort); err != nil {
+			return fmt.Errorf("failed to create workspace: %w", err)
+		}
+		manifest, err := helpers.LoadWorkspaceManifest(name)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest of workspace %s: %w", name, err)
+		}
+
+		log.Printf("✅ Created workspace %s in %s, node0 will listen on port %d\n", name, helpers.WorkspaceDataDir(name), manifest.NodeBaseHTTPPort())
+		log.Printf("Run `workspace switch %s` or pass --workspace %s to use it\n", name, name)
+		return nil
+	},
+}
+
+var workspaceSwitchCmd = &cobra.Command{
+	Use:         "switch <name>",
+	Annotations: offline,
+	Short:       "Make a workspace the active one",
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		exists, err := helpers.WorkspaceExists(name)
+		if err != nil {
+			return fmt.Errorf("failed to check if workspace %s exists: %w", name, err)
+		}
+		if !exists {
+			return fmt.Errorf("workspace %s does not exist", name)
+		}
+		if err := helpers.SetActiveWorkspace(name); err != nil {
+			return fmt.Errorf("failed to switch workspace: %w", err)
+		}
+
+		log.Printf("✅ Switched to workspace %s\n", name)
+		return nil
+	},
+}
+
+var workspaceDeleteCmd = &cobra.Command{
+	Use:         "delete <name>",
+	Annotations: offline,
+	Short:       "Remove the containers of a workspace and delete all of its state",
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		exists, err := helpers.WorkspaceExists(name)
+		if err != nil {
+			return fmt.Errorf("failed to check if workspace %s exists: %w", name, err)
+		}
+		if !exists {
+			return fmt.Errorf("workspace %s does not exist", name)
+		}
+
+		if !workspaceDeleteForced {
+			manifest, err := helpers.LoadWorkspaceManifest(name)
+			if err != nil {
+				return fmt.Errorf("failed to load manifest of workspace %s: %w", name, err)
+			}
+			if manifest.SubnetID != ids.Empty {
+				return fmt.Errorf("workspace %s holds subnet %s, pass --force to delete it anyway", name, manifest.SubnetID)
+			}
+			hasKeys, err := helpers.WorkspaceHasKeys(name)
+			if err != nil {
+				return fmt.Errorf("failed to check keys of workspace %s: %w", name, err)
+			}
+			if hasKeys {
+				return fmt.Errorf("workspace %s holds keys, pass --force to delete it anyway", name)
+			}
+		}
+
+		if name == helpers.DefaultWorkspace {
+			return fmt.Errorf("the default workspace can't be deleted, use `down --purge` to reset it")
+		}
+		// The nodes of the workspace run from its data directory
+		if err := removeWorkspaceContainers(name); err != nil {
+			return err
+		}
+		if err := helpers.DeleteWorkspace(name); err != nil {
+			return fmt.Errorf("failed to delete workspace: %w", err)
+		}
+
+		log.Printf("✅ Deleted workspace %s\n", name)
+		return nil
+	},
+}
@@ -1,11 +1,20 @@
 package config
 
 const (
-	RPC_URL                   = "https://api.avax-test.network"
 	L1_CHAIN_ID               = 12345
 	ProxyContractAddress      = "0xFEEDC0DE0000000000000000000000000000000"
 	ProxyAdminContractAddress = "0xC0FFEE1234567890aBcDEF1234567890AbCdEf34"
 
+	// Fixed addresses of the validator manager implementation and what it
+	// needs when generate-genesis --manager-in-genesis embeds them
+	ValidatorManagerImplementationAddress = "0x0C0DEBA5E0000000000000000000000000000000"
+	ValidatorMessagesAddress              = "0x0C0DEBA5E0000000000000000000000000000001"
+	RewardCalculatorAddress               = "0x0DEADC0DE0000000000000000000000000000000"
+
 	PoSNativeMode = "pos-native"
 	PoAMode       = "poa"
+
+	ProxyOZ4Transparent = "oz4.9-transparent"
+	ProxyOZ5Transparent = "oz5-transparent"
+	ProxyUUPS           = "uups"
 )
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+)
+
+const (
+	FujiNetwork    = "fuji"
+	MainnetNetwork = "mainnet"
+	LocalNetwork   = "local"
+	CustomNetwork  = "custom"
+)
+
+// Network is a named profile of the primary network endpoints a command talks to
+type Network struct {
+	Name            string
+	PChainURI       string // Base API URI of a primary network node, e.g. https://api.avax-test.network
+	NetworkID       uint32
+	CChainURL       string
+	AggregatorPeers []string // Extra node URIs the warp signature aggregator connects to, on top of the workspace's node0
+}
+
+var networks = map[string]Network{
+	FujiNetwork: {
+		Name:      FujiNetwork,
+		PChainURI: "https://api.avax-test.network",
+		NetworkID: avagoconstants.FujiID,
+		CChainURL: "https://api.avax-test.network/ext/bc/C/rpc",
+	},
+	MainnetNetwork: {
+		Name:      MainnetNetwork,
+		PChainURI: "https://api.avax.network",
+		NetworkID: avagoconstants.MainnetID,
+		CChainURL: "https://api.avax.network/ext/bc/C/rpc",
+	},
+	// A local network has no fixed URI: 9650 and the ports after it belong to
+	// the nodes of the workspaces, so its URI comes from --rpc-url
+	LocalNetwork: {
+		Name:      LocalNetwork,
+		NetworkID: avagoconstants.LocalID,
+	},
+}
+
+// NetworkNames returns the names of all selectable network profiles
+func NetworkNames() []string {
+	names := []string{CustomNetwork}
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetNetwork returns a predefined network profile by name
+func GetNetwork(name string) (Network, error) {
+	network, ok := networks[name]
+	if !ok {
+		return Network{}, fmt.Errorf("unknown network %q, must be one of: %s", name, strings.Join(NetworkNames(), ", "))
+	}
+	network.AggregatorPeers = append([]string{}, network.AggregatorPeers...)
+	return network, nil
+}
+
+// NewCustomNetwork builds a profile for a network that is reachable at uri
+func NewCustomNetwork(uri string, networkID uint32) Network {
+	uri = strings.TrimSuffix(uri, "/")
+	return Network{
+		Name:      CustomNetwork,
+		PChainURI: uri,
+		NetworkID: networkID,
+		CChainURL: uri + "/ext/bc/C/rpc",
+	}
+}
+
+// PChainEndpoint is the JSON-RPC endpoint of the P-chain API
+func (n Network) PChainEndpoint() string {
+	return n.PChainURI + "/ext/P"
+}
@@ -0,0 +1,274 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ContractArtifact is a contract compiled by Foundry (out/<file>.sol/<name>.json)
+// or Hardhat (artifacts/.../<name>.json)
+type ContractArtifact struct {
+	Name string
+	ABI  abi.ABI
+	// bytecode is the hex creation code, with placeholders where libraries
+	// are linked
+	bytecode string
+	// linkReferences are the byte offsets of each library address, by the
+	// fully qualified <source file>:<name> of the library
+	linkReferences map[string][]LinkReference
+}
+
+type LinkReference struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+type artifactBytecode struct {
+	Object         string                                `json:"object"`
+	LinkReferences map[string]map[string][]LinkReference `json:"linkReferences"`
+}
+
+// LoadContractArtifact reads a Foundry or Hardhat artifact. Foundry nests the
+// link references in the bytecode object, Hardhat keeps them next to a
+// bytecode string.
+func LoadContractArtifact(path string) (*ContractArtifact, error) {
+	artifactBytes, err := LoadBytes(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading artifact: %w", err)
+	}
+	var raw struct {
+		ContractName   string                                `json:"contractName"`
+		ABI            json.RawMessage                       `json:"abi"`
+		Bytecode       json.RawMessage                       `json:"bytecode"`
+		LinkReferences map[string]map[string][]LinkReference `json:"linkReferences"`
+	}
+	if err := json.Unmarshal(artifactBytes, &raw); err != nil {
+		return nil, fmt.Errorf("parsing artifact %s: %w", path, err)
+	}
+	if len(raw.ABI) == 0 || len(raw.Bytecode) == 0 {
+		return nil, fmt.Errorf("artifact %s has no abi or bytecode, expected a Foundry or Hardhat contract artifact", path)
+	}
+
+	bytecode := artifactBytecode{LinkReferences: raw.LinkReferences}
+	if err := json.Unmarshal(raw.Bytecode, &bytecode.Object); err != nil {
+		if err := json.Unmarshal(raw.Bytecode, &bytecode); err != nil {
+			return nil, fmt.Errorf("parsing bytecode of artifact %s: %w", path, err)
+		}
+	}
+	bytecode.Object = strings.TrimPrefix(bytecode.Object, "0x")
+	if bytecode.Object == "" {
+		return nil, fmt.Errorf("artifact %s has empty bytecode, an interface or abstract contract can't be deployed", path)
+	}
+
+	artifact := &ContractArtifact{
+		Name:           raw.ContractName,
+		bytecode:       bytecode.Object,
+		linkReferences: map[string][]LinkReference{},
+	}
+	if artifact.Name == "" {
+		artifact.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	artifact.ABI, err = abi.JSON(bytes.NewReader(raw.ABI))
+	if err != nil {
+		return nil, fmt.Errorf("parsing abi of artifact %s: %w", path, err)
+	}
+	for file, libraries := range bytecode.LinkReferences {
+		for name, references := range libraries {
+			artifact.linkReferences[file+":"+name] = references
+		}
+	}
+	return artifact, nil
+}
+
+// Libraries lists the fully qualified <source file>:<name> of the libraries
+// the bytecode must be linked with
+func (a *ContractArtifact) Libraries() []string {
+	names := []string{}
+	for name := range a.linkReferences {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveLibrary returns the fully qualified name of the library the
+// contract links as name, which is either fully qualified or a bare name
+// used by a single source file
+func (a *ContractArtifact) ResolveLibrary(name string) (string, error) {
+	if _, ok := a.linkReferences[name]; ok {
+		return name, nil
+	}
+	matches := []string{}
+	for _, qualified := range a.Libraries() {
+		if qualified[strings.LastIndex(qualified, ":")+1:] == name {
+			matches = append(matches, qualified)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%s doesn't use library %s, it uses %v", a.Name, name, a.Libraries())
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%s links more than one library named %s, use one of %v", a.Name, name, matches)
+	}
+}
+
+// Link returns the creation code with the address of each library filled in.
+// Libraries are named as ResolveLibrary accepts.
+func (a *ContractArtifact) Link(libraries map[string]common.Address) ([]byte, error) {
+	addresses := map[string]common.Address{}
+	for name, address := range libraries {
+		qualified, err := a.ResolveLibrary(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := addresses[qualified]; ok {
+			return nil, fmt.Errorf("library %s of %s is given more than once", qualified, a.Name)
+		}
+		addresses[qualified] = address
+	}
+
+	bytecode := []byte(a.bytecode)
+	for name, references := range a.linkReferences {
+		address, ok := addresses[name]
+		if !ok {
+			return nil, fmt.Errorf("%s needs the address of library %s", a.Name, name)
+		}
+		for _, reference := range references {
+			start, end := 2*reference.Start, 2*(reference.Start+reference.Length)
+			if reference.Length != common.AddressLength || end > len(bytecode) {
+				return nil, fmt.Errorf("invalid link reference of %s in %s at %d", name, a.Name, reference.Start)
+			}
+			copy(bytecode[start:end], hex.EncodeToString(address.Bytes()))
+		}
+	}
+
+	code, err := hex.DecodeString(string(bytecode))
+	if err != nil {
+		return nil, fmt.Errorf("%s has unlinked or invalid bytecode: %w", a.Name, err)
+	}
+	return code, nil
+}
+
+// ConstructorArgs converts a JSON array into the constructor arguments of the
+// contract. Addresses, bytes and hex numbers are strings, other numbers can be
+// either. Tuples and arrays aren't supported.
+func (a *ContractArtifact) ConstructorArgs(jsonArgs string) ([]interface{}, error) {
+	inputs := a.ABI.Constructor.Inputs
+	values := []interface{}{}
+	if strings.TrimSpace(jsonArgs) != "" {
+		decoder := json.NewDecoder(strings.NewReader(jsonArgs))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("constructor arguments must be a JSON array: %w", err)
+		}
+	}
+	if len(values) != len(inputs) {
+		return nil, fmt.Errorf("the constructor of %s takes %d arguments (%s), got %d", a.Name, len(inputs), argumentsSignature(inputs), len(values))
+	}
+
+	args := make([]interface{}, len(inputs))
+	for i, input := range inputs {
+		arg, err := abiValue(input.Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("constructor argument %d (%s %s): %w", i, input.Type, input.Name, err)
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+func argumentsSignature(inputs abi.Arguments) string {
+	types := make([]string, len(inputs))
+	for i, input := range inputs {
+		types[i] = strings.TrimSpace(input.Type.String() + " " + input.Name)
+	}
+	return strings.Join(types, ", ")
+}
+
+// abiValue converts a decoded JSON value into the Go type abi.Pack expects
+// for typ
+func abiValue(typ abi.Type, value interface{}) (interface{}, error) {
+	switch typ.T {
+	case abi.AddressTy:
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("expected a 0x hex address, got %v", value)
+		}
+		return common.HexToAddress(s), nil
+	case abi.BoolTy:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected true or false, got %v", value)
+		}
+		return b, nil
+	case abi.StringTy:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %v", value)
+		}
+		return s, nil
+	case abi.BytesTy, abi.FixedBytesTy:
+		s, ok := value.(string)
+		if !ok || !strings.HasPrefix(s, "0x") {
+			return nil, fmt.Errorf("expected 0x hex bytes, got %v", value)
+		}
+		b, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return nil, err
+		}
+		if typ.T == abi.BytesTy {
+			return b, nil
+		}
+		if len(b) != typ.Size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", typ.Size, len(b))
+		}
+		fixed := reflect.New(typ.GetType()).Elem()
+		reflect.Copy(fixed, reflect.ValueOf(b))
+		return fixed.Interface(), nil
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int), false
+		switch v := value.(type) {
+		case json.Number:
+			n, ok = n.SetString(v.String(), 10)
+		case string:
+			n, ok = parseInteger(v)
+		}
+		if !ok {
+			return nil, fmt.Errorf("expected an integer, got %v", value)
+		}
+		// A signed type holds one more negative number than positive ones,
+		// -n-1 has to fit like a positive n
+		bits, magnitude := typ.Size, n
+		if typ.T == abi.IntTy {
+			bits--
+			if n.Sign() < 0 {
+				magnitude = new(big.Int).Not(n)
+			}
+		}
+		if (typ.T == abi.UintTy && n.Sign() < 0) || magnitude.BitLen() > bits {
+			return nil, fmt.Errorf("%s doesn't fit in %s", n, typ)
+		}
+		goType := typ.GetType()
+		if goType == reflect.TypeOf(n) {
+			return n, nil
+		}
+		if typ.T == abi.UintTy {
+			return reflect.ValueOf(n.Uint64()).Convert(goType).Interface(), nil
+		}
+		return reflect.ValueOf(n.Int64()).Convert(goType).Interface(), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
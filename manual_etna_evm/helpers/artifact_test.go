@@ -0,0 +1,199 @@
+package helpers
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testArtifactABI = `[{"type":"constructor","inputs":[
+	{"name":"owner","type":"address"},
+	{"name":"small","type":"uint8"},
+	{"name":"signed","type":"int8"},
+	{"name":"large","type":"uint256"},
+	{"name":"id","type":"bytes32"},
+	{"name":"data","type":"bytes"},
+	{"name":"enabled","type":"bool"},
+	{"name":"name","type":"string"}
+]}]`
+
+// testArtifactPlaceholder is where the library address goes, 20 bytes after
+// the 2 byte prefix
+var testArtifactPlaceholder = "__$0123456789abcdef0123456789abcdef01$__"
+
+func writeTestArtifact(t *testing.T, name string, content string) *ContractArtifact {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := LoadContractArtifact(path)
+	if err != nil {
+		t.Fatalf("loading %s: %s", name, err)
+	}
+	return artifact
+}
+
+func TestContractArtifactLink(t *testing.T) {
+	foundry := writeTestArtifact(t, "Manager.json", `{
+		"abi": `+testArtifactABI+`,
+		"bytecode": {
+			"object": "0x6080`+testArtifactPlaceholder+`00",
+			"linkReferences": {"src/Lib.sol": {"Lib": [{"start": 2, "length": 20}]}}
+		}
+	}`)
+	hardhat := writeTestArtifact(t, "Manager.json", `{
+		"contractName": "Manager",
+		"abi": `+testArtifactABI+`,
+		"bytecode": "0x6080`+testArtifactPlaceholder+`00",
+		"linkReferences": {"contracts/Lib.sol": {"Lib": [{"start": 2, "length": 20}]}}
+	}`)
+
+	library := common.HexToAddress("0x00000000000000000000000000000000000001ab")
+	want := append(append([]byte{0x60, 0x80}, library.Bytes()...), 0x00)
+	for name, test := range map[string]struct {
+		artifact  *ContractArtifact
+		qualified string
+	}{
+		"foundry": {foundry, "src/Lib.sol:Lib"},
+		"hardhat": {hardhat, "contracts/Lib.sol:Lib"},
+	} {
+		artifact := test.artifact
+		t.Run(name, func(t *testing.T) {
+			if artifact.Name != "Manager" {
+				t.Fatalf("name is %q, want Manager", artifact.Name)
+			}
+			if libraries := artifact.Libraries(); len(libraries) != 1 || libraries[0] != test.qualified {
+				t.Fatalf("libraries are %v, want [%s]", libraries, test.qualified)
+			}
+			for _, libraryName := range []string{"Lib", test.qualified} {
+				code, err := artifact.Link(map[string]common.Address{libraryName: library})
+				if err != nil {
+					t.Fatalf("linking %s: %s", libraryName, err)
+				}
+				if !bytes.Equal(code, want) {
+					t.Fatalf("code linked with %s is %x, want %x", libraryName, code, want)
+				}
+			}
+			if _, err := artifact.Link(map[string]common.Address{"Lib": library, test.qualified: library}); err == nil {
+				t.Fatal("linked a library given twice")
+			}
+			if _, err := artifact.Link(nil); err == nil || !strings.Contains(err.Error(), "Lib") {
+				t.Fatalf("linking without the library gives %v", err)
+			}
+			if _, err := artifact.Link(map[string]common.Address{"Lib": library, "Other": library}); err == nil {
+				t.Fatal("linked a library the contract doesn't use")
+			}
+		})
+	}
+
+	// Linking doesn't change the artifact
+	if _, err := foundry.Link(map[string]common.Address{"Lib": library}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := foundry.Link(map[string]common.Address{"Lib": common.HexToAddress("0x02")}); err != nil {
+		t.Fatalf("linking twice: %s", err)
+	}
+}
+
+func TestContractArtifactLinkSameNameInTwoFiles(t *testing.T) {
+	second := strings.Replace(testArtifactPlaceholder, "0123", "4567", 1)
+	artifact := writeTestArtifact(t, "Manager.json", `{
+		"abi": [],
+		"bytecode": {
+			"object": "0x6080`+testArtifactPlaceholder+second+`",
+			"linkReferences": {
+				"src/a/Lib.sol": {"Lib": [{"start": 2, "length": 20}]},
+				"src/b/Lib.sol": {"Lib": [{"start": 22, "length": 20}]}
+			}
+		}
+	}`)
+
+	first, other := common.HexToAddress("0x0a"), common.HexToAddress("0x0b")
+	if _, err := artifact.Link(map[string]common.Address{"Lib": first}); err == nil || !strings.Contains(err.Error(), "more than one") {
+		t.Fatalf("linking the ambiguous bare name gives %v", err)
+	}
+	code, err := artifact.Link(map[string]common.Address{"src/a/Lib.sol:Lib": first, "src/b/Lib.sol:Lib": other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]byte{0x60, 0x80}, first.Bytes()...), other.Bytes()...)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("linked code is %x, want %x", code, want)
+	}
+}
+
+func TestLoadContractArtifactRejects(t *testing.T) {
+	for name, content := range map[string]string{
+		"no bytecode":    `{"abi": []}`,
+		"empty bytecode": `{"abi": [], "bytecode": "0x"}`,
+		"not json":       `abi`,
+	} {
+		path := filepath.Join(t.TempDir(), "Contract.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadContractArtifact(path); err == nil {
+			t.Fatalf("artifact with %s was loaded", name)
+		}
+	}
+}
+
+func TestContractArtifactConstructorArgs(t *testing.T) {
+	artifact := writeTestArtifact(t, "Manager.json", `{"abi": `+testArtifactABI+`, "bytecode": "0x6080"}`)
+	large, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	args, err := artifact.ConstructorArgs(`["0x00000000000000000000000000000000000000aa", 255, "-128", "123456789012345678901234567890", "0x` + strings.Repeat("11", 32) + `", "0xbeef", true, "manager"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0] != common.HexToAddress("0xaa") || args[1] != uint8(255) || args[2] != int8(-128) || args[3].(*big.Int).Cmp(large) != 0 {
+		t.Fatalf("arguments are %v", args)
+	}
+	if args[4] != [32]byte(bytes.Repeat([]byte{0x11}, 32)) || !bytes.Equal(args[5].([]byte), []byte{0xbe, 0xef}) || args[6] != true || args[7] != "manager" {
+		t.Fatalf("arguments are %v", args)
+	}
+	if _, err := artifact.ABI.Pack("", args...); err != nil {
+		t.Fatalf("packing the arguments: %s", err)
+	}
+
+	// Hex and zero-padded strings are integers too, never octal
+	args, err = artifact.ConstructorArgs(`["0x00000000000000000000000000000000000000aa", "0x10", "010", 0, "0x` + strings.Repeat("00", 32) + `", "0x", false, ""]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[1] != uint8(16) || args[2] != int8(10) {
+		t.Fatalf("small and signed are %v and %v, want 16 and 10", args[1], args[2])
+	}
+
+	valid := []string{`"0x00000000000000000000000000000000000000aa"`, "1", "1", "1", `"0x` + strings.Repeat("11", 32) + `"`, `"0x"`, "true", `""`}
+	invalid := map[int][]string{
+		0: {`"0xaa"`, "1"},
+		1: {"256", "-1", "1.5", `"one"`},
+		2: {"128", "-129"},
+		4: {`"0x11"`, `"11"`},
+		5: {`"beef"`},
+		6: {`"true"`},
+		7: {"1"},
+	}
+	for i, values := range invalid {
+		for _, value := range values {
+			args := append([]string{}, valid...)
+			args[i] = value
+			if _, err := artifact.ConstructorArgs("[" + strings.Join(args, ",") + "]"); err == nil {
+				t.Fatalf("argument %d accepted %s", i, value)
+			}
+		}
+	}
+	if _, err := artifact.ConstructorArgs(`[]`); err == nil {
+		t.Fatal("accepted no arguments for a constructor taking 8")
+	}
+	if _, err := artifact.ConstructorArgs(`{}`); err == nil {
+		t.Fatal("accepted an object")
+	}
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// BalanceSample is the P-chain balance of an L1 validator at a chain time
+type BalanceSample struct {
+	Balance   uint64    `json:"balance"`
+	ChainTime time.Time `json:"chainTime"`
+}
+
+// LoadBalanceSamples reads the last balance watch-balances saw for each
+// validation ID, or an empty map if it never ran in this workspace
+func LoadBalanceSamples() (map[ids.ID]BalanceSample, error) {
+	samples := map[ids.ID]BalanceSample{}
+	path := BalanceSamplesPath()
+	exists, err := FileExists(path)
+	if err != nil || !exists {
+		return samples, err
+	}
+	samplesBytes, err := LoadBytes(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(samplesBytes, &samples); err != nil {
+		return nil, fmt.Errorf("parsing balance samples %s: %w", path, err)
+	}
+	return samples, nil
+}
+
+// SaveBalanceSamples atomically replaces the samples of LoadBalanceSamples
+func SaveBalanceSamples(samples map[ids.ID]BalanceSample) error {
+	samplesBytes, err := json.MarshalIndent(samples, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling balance samples: %w", err)
+	}
+	return SaveBytesAtomic(BalanceSamplesPath(), samplesBytes)
+}
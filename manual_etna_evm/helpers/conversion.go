@@ -0,0 +1,81 @@
+package helpers
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ConversionData is what the P-chain hashed into the conversion ID of the
+// subnet, as it was in the conversion tx
+type ConversionData struct {
+	SubnetID       ids.ID                `json:"subnetID"`
+	ManagerChainID ids.ID                `json:"managerChainID"`
+	ManagerAddress hexutil.Bytes         `json:"managerAddress"`
+	Validators     []ConversionValidator `json:"validators"`
+}
+
+// ConversionValidator is a bootstrap validator, at the index of its
+// validation ID
+type ConversionValidator struct {
+	NodeID       hexutil.Bytes `json:"nodeID"`
+	BLSPublicKey hexutil.Bytes `json:"blsPublicKey"`
+	Weight       uint64        `json:"weight"`
+}
+
+// ConversionDataFromTx extracts the conversion data of a ConvertSubnetToL1Tx
+func ConversionDataFromTx(tx *txs.ConvertSubnetToL1Tx) *ConversionData {
+	data := &ConversionData{
+		SubnetID:       tx.Subnet,
+		ManagerChainID: tx.ChainID,
+		ManagerAddress: append(hexutil.Bytes{}, tx.Address...),
+		Validators:     make([]ConversionValidator, len(tx.Validators)),
+	}
+	for i, validator := range tx.Validators {
+		data.Validators[i] = ConversionValidator{
+			NodeID:       append(hexutil.Bytes{}, validator.NodeID...),
+			BLSPublicKey: append(hexutil.Bytes{}, validator.Signer.PublicKey[:]...),
+			Weight:       validator.Weight,
+		}
+	}
+	return data
+}
+
+// Message converts the data into the form the conversion ID and the warp
+// message are computed from
+func (d *ConversionData) Message() (message.SubnetToL1ConversionData, error) {
+	validators := make([]message.SubnetToL1ConverstionValidatorData, len(d.Validators))
+	for i, validator := range d.Validators {
+		if len(validator.BLSPublicKey) != bls.PublicKeyLen {
+			return message.SubnetToL1ConversionData{}, fmt.Errorf("BLS public key of validator %d has %d bytes, expected %d", i, len(validator.BLSPublicKey), bls.PublicKeyLen)
+		}
+		validators[i] = message.SubnetToL1ConverstionValidatorData{
+			NodeID:       []byte(validator.NodeID),
+			BLSPublicKey: [bls.PublicKeyLen]byte(validator.BLSPublicKey),
+			Weight:       validator.Weight,
+		}
+	}
+	return message.SubnetToL1ConversionData{
+		SubnetID:       d.SubnetID,
+		ManagerChainID: d.ManagerChainID,
+		ManagerAddress: []byte(d.ManagerAddress),
+		Validators:     validators,
+	}, nil
+}
+
+// ID computes the conversion ID the P-chain reports for the data
+func (d *ConversionData) ID() (ids.ID, error) {
+	data, err := d.Message()
+	if err != nil {
+		return ids.Empty, err
+	}
+	id, err := message.SubnetToL1ConversionID(data)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("computing conversion ID: %w", err)
+	}
+	return id, nil
+}
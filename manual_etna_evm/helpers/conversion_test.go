@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
+)
+
+// testConvertTx is a conversion with validators sorted by node ID, like the
+// P-chain requires
+func testConvertTx(t *testing.T, validatorCount int) *txs.ConvertSubnetToL1Tx {
+	t.Helper()
+	validators := make([]*txs.ConvertSubnetToL1Validator, validatorCount)
+	for i := range validators {
+		sk, err := bls.NewSecretKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		validators[i] = &txs.ConvertSubnetToL1Validator{
+			NodeID:  ids.GenerateTestNodeID().Bytes(),
+			Weight:  uint64(100 * (i + 1)),
+			Balance: 1,
+			Signer:  *signer.NewProofOfPossession(sk),
+		}
+	}
+	utils.Sort(validators)
+	return &txs.ConvertSubnetToL1Tx{
+		Subnet:     ids.GenerateTestID(),
+		ChainID:    ids.GenerateTestID(),
+		Address:    []byte{0xfe, 0xed, 0xc0, 0xde},
+		Validators: validators,
+	}
+}
+
+// pChainConversionID computes the conversion ID the way the P-chain does
+// when it executes tx
+func pChainConversionID(t *testing.T, tx *txs.ConvertSubnetToL1Tx) ids.ID {
+	t.Helper()
+	validators := make([]message.SubnetToL1ConverstionValidatorData, len(tx.Validators))
+	for i, validator := range tx.Validators {
+		validators[i] = message.SubnetToL1ConverstionValidatorData{
+			NodeID:       validator.NodeID,
+			BLSPublicKey: validator.Signer.PublicKey,
+			Weight:       validator.Weight,
+		}
+	}
+	id, err := message.SubnetToL1ConversionID(message.SubnetToL1ConversionData{
+		SubnetID:       tx.Subnet,
+		ManagerChainID: tx.ChainID,
+		ManagerAddress: tx.Address,
+		Validators:     validators,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestConversionDataID(t *testing.T) {
+	for _, validatorCount := range []int{1, 3} {
+		tx := testConvertTx(t, validatorCount)
+		want := pChainConversionID(t, tx)
+
+		data := ConversionDataFromTx(tx)
+		got, err := data.ID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("conversion ID with %d validators is %s, the P-chain computes %s", validatorCount, got, want)
+		}
+
+		// The manifest keeps the data as JSON
+		dataJSON, err := json.Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored := &ConversionData{}
+		if err := json.Unmarshal(dataJSON, stored); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := stored.ID(); err != nil || got != want {
+			t.Fatalf("conversion ID after a JSON round trip is %s (%v), want %s", got, err, want)
+		}
+	}
+}
+
+func TestConversionDataIDChanges(t *testing.T) {
+	tx := testConvertTx(t, 2)
+	id, err := ConversionDataFromTx(tx).ID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := map[string]func(data *ConversionData){
+		"weight": func(data *ConversionData) { data.Validators[0].Weight++ },
+		"validator order": func(data *ConversionData) {
+			data.Validators[0], data.Validators[1] = data.Validators[1], data.Validators[0]
+		},
+		"manager address": func(data *ConversionData) { data.ManagerAddress = append(data.ManagerAddress, 0) },
+		"manager chain":   func(data *ConversionData) { data.ManagerChainID = ids.GenerateTestID() },
+	}
+	for name, change := range changes {
+		data := ConversionDataFromTx(tx)
+		change(data)
+		changed, err := data.ID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed == id {
+			t.Fatalf("changing the %s keeps conversion ID %s", name, id)
+		}
+	}
+
+	data := ConversionDataFromTx(tx)
+	data.Validators[1].BLSPublicKey = data.Validators[1].BLSPublicKey[1:]
+	if _, err := data.ID(); err == nil {
+		t.Fatal("hashed a truncated BLS public key")
+	}
+}
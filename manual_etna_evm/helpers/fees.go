@@ -0,0 +1,214 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpMessage "github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+)
+
+// simulatedFunds is the single UTXO every estimate spends from, large enough
+// for any tx so that the estimate never depends on the current balance
+const simulatedFunds = math.MaxUint64 / 2
+
+// FeeEstimate is what a P-chain tx takes from the fee payer
+type FeeEstimate struct {
+	Name string
+	// Fee is burned by the P-chain
+	Fee uint64
+	// Balance goes to the continuous fee balance of L1 validators
+	Balance uint64
+}
+
+// Total is everything the fee payer spends on the tx
+func (e FeeEstimate) Total() uint64 {
+	return e.Fee + e.Balance
+}
+
+// FeeEstimator builds P-chain txs like the wallet would, without issuing
+// them, to learn their fee at the current gas price
+type FeeEstimator struct {
+	context *pbuilder.Context
+	payer   ids.ShortID
+}
+
+func NewFeeEstimator(context *pbuilder.Context, payer ids.ShortID) *FeeEstimator {
+	return &FeeEstimator{context: context, payer: payer}
+}
+
+func (e *FeeEstimator) CreateSubnet(owner *secp256k1fx.OutputOwners) (FeeEstimate, error) {
+	return e.estimate("create subnet", nil, 0, func(b pbuilder.Builder, _ ids.ID) (txs.UnsignedTx, error) {
+		return b.NewCreateSubnetTx(owner)
+	})
+}
+
+func (e *FeeEstimator) CreateChain(owner *secp256k1fx.OutputOwners, genesis []byte, vmID ids.ID, chainName string) (FeeEstimate, error) {
+	return e.estimate("create chain", owner, 0, func(b pbuilder.Builder, subnetID ids.ID) (txs.UnsignedTx, error) {
+		return b.NewCreateChainTx(subnetID, genesis, vmID, nil, chainName)
+	})
+}
+
+// ConvertSubnetToL1 estimates a conversion with one bootstrap validator per
+// entry of balances
+func (e *FeeEstimator) ConvertSubnetToL1(owner *secp256k1fx.OutputOwners, managerAddress []byte, balances []uint64) (FeeEstimate, error) {
+	validators := make([]*txs.ConvertSubnetToL1Validator, len(balances))
+	total := uint64(0)
+	for i, balance := range balances {
+		nodeID := ids.GenerateTestNodeID()
+		validators[i] = &txs.ConvertSubnetToL1Validator{
+			NodeID:                nodeID[:],
+			Weight:                1,
+			Balance:               balance,
+			Signer:                signer.ProofOfPossession{},
+			RemainingBalanceOwner: warpMessage.PChainOwner{Threshold: 1, Addresses: []ids.ShortID{e.payer}},
+			DeactivationOwner:     warpMessage.PChainOwner{Threshold: 1, Addresses: []ids.ShortID{e.payer}},
+		}
+		total += balance
+	}
+	name := fmt.Sprintf("convert to L1 with %d validator(s)", len(balances))
+	return e.estimate(name, owner, total, func(b pbuilder.Builder, subnetID ids.ID) (txs.UnsignedTx, error) {
+		return b.NewConvertSubnetToL1Tx(subnetID, ids.GenerateTestID(), managerAddress, validators)
+	})
+}
+
+// RegisterL1Validator estimates a registration signed by signers validators of the L1
+func (e *FeeEstimator) RegisterL1Validator(balance uint64, signers int) (FeeEstimate, error) {
+	owner := warpMessage.PChainOwner{Threshold: 1, Addresses: []ids.ShortID{e.payer}}
+	payload, err := warpMessage.NewRegisterL1Validator(ids.GenerateTestID(), ids.GenerateTestNodeID(), [bls.PublicKeyLen]byte{}, 0, owner, owner, 1)
+	if err != nil {
+		return FeeEstimate{}, fmt.Errorf("building register validator message: %w", err)
+	}
+	message, err := e.warpMessage(payload.Bytes(), signers)
+	if err != nil {
+		return FeeEstimate{}, err
+	}
+	return e.estimate("register validator", nil, balance, func(b pbuilder.Builder, _ ids.ID) (txs.UnsignedTx, error) {
+		return b.NewRegisterL1ValidatorTx(balance, [bls.SignatureLen]byte{}, message)
+	})
+}
+
+// SetL1ValidatorWeight estimates a weight change signed by signers validators of the L1
+func (e *FeeEstimator) SetL1ValidatorWeight(signers int) (FeeEstimate, error) {
+	payload, err := warpMessage.NewL1ValidatorWeight(ids.GenerateTestID(), 1, 0)
+	if err != nil {
+		return FeeEstimate{}, fmt.Errorf("building validator weight message: %w", err)
+	}
+	message, err := e.warpMessage(payload.Bytes(), signers)
+	if err != nil {
+		return FeeEstimate{}, err
+	}
+	return e.estimate("set validator weight", nil, 0, func(b pbuilder.Builder, _ ids.ID) (txs.UnsignedTx, error) {
+		return b.NewSetL1ValidatorWeightTx(message)
+	})
+}
+
+func (e *FeeEstimator) IncreaseL1ValidatorBalance(balance uint64) (FeeEstimate, error) {
+	return e.estimate("increase validator balance", nil, balance, func(b pbuilder.Builder, _ ids.ID) (txs.UnsignedTx, error) {
+		return b.NewIncreaseL1ValidatorBalanceTx(ids.GenerateTestID(), balance)
+	})
+}
+
+// DisableL1Validator estimates disabling a validator deactivated by owner
+func (e *FeeEstimator) DisableL1Validator(owner *secp256k1fx.OutputOwners) (FeeEstimate, error) {
+	return e.estimate("disable validator", owner, 0, func(b pbuilder.Builder, validationID ids.ID) (txs.UnsignedTx, error) {
+		return b.NewDisableL1ValidatorTx(validationID)
+	})
+}
+
+// warpMessage wraps payload like the validator manager and the signature
+// aggregator would, so the message has the size of a real one
+func (e *FeeEstimator) warpMessage(payload []byte, signers int) ([]byte, error) {
+	addressedCall, err := warpPayload.NewAddressedCall(make([]byte, 20), payload)
+	if err != nil {
+		return nil, fmt.Errorf("building addressed call: %w", err)
+	}
+	unsignedMessage, err := warp.NewUnsignedMessage(e.context.NetworkID, ids.GenerateTestID(), addressedCall.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("building warp message: %w", err)
+	}
+	signerBits := set.NewBits()
+	for i := 0; i < signers; i++ {
+		signerBits.Add(i)
+	}
+	message, err := warp.NewMessage(unsignedMessage, &warp.BitSetSignature{Signers: signerBits.Bytes()})
+	if err != nil {
+		return nil, fmt.Errorf("building warp message: %w", err)
+	}
+	return message.Bytes(), nil
+}
+
+// estimate builds a tx paid from a simulated UTXO of the payer. owner, if
+// set, owns the subnet or the validator the tx authorizes for. The fee is what the tx consumes
+// beyond its outputs and the balance it hands to validators.
+func (e *FeeEstimator) estimate(
+	name string,
+	owner *secp256k1fx.OutputOwners,
+	balance uint64,
+	build func(b pbuilder.Builder, subnetID ids.ID) (txs.UnsignedTx, error),
+) (FeeEstimate, error) {
+	addrs := set.Of(e.payer)
+	backend := &simulatedBackend{
+		utxo: &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: e.context.AVAXAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt:          simulatedFunds,
+				OutputOwners: secp256k1fx.OutputOwners{Threshold: 1, Addrs: []ids.ShortID{e.payer}},
+			},
+		},
+		owners: map[ids.ID]fx.Owner{},
+	}
+	subnetID := ids.GenerateTestID()
+	if owner != nil {
+		backend.owners[subnetID] = owner
+		addrs.Add(owner.Addrs...)
+	}
+
+	utx, err := build(pbuilder.New(addrs, e.context, backend), subnetID)
+	if err != nil {
+		return FeeEstimate{}, fmt.Errorf("building %s tx: %w", name, err)
+	}
+	produced := uint64(0)
+	for _, out := range utx.Outputs() {
+		produced += out.Output().Amount()
+	}
+	return FeeEstimate{
+		Name:    name,
+		Fee:     simulatedFunds - produced - balance,
+		Balance: balance,
+	}, nil
+}
+
+type simulatedBackend struct {
+	utxo   *avax.UTXO
+	owners map[ids.ID]fx.Owner
+}
+
+func (b *simulatedBackend) UTXOs(_ context.Context, sourceChainID ids.ID) ([]*avax.UTXO, error) {
+	if sourceChainID != constants.PlatformChainID {
+		return nil, nil
+	}
+	return []*avax.UTXO{b.utxo}, nil
+}
+
+func (b *simulatedBackend) GetOwner(_ context.Context, ownerID ids.ID) (fx.Owner, error) {
+	owner, ok := b.owners[ownerID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return owner, nil
+}
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/gas"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+)
+
+var testAVAXAssetID = ids.GenerateTestID()
+
+func testFeeEstimator(gasPrice gas.Price) *FeeEstimator {
+	return NewFeeEstimator(&pbuilder.Context{
+		NetworkID:   5,
+		AVAXAssetID: testAVAXAssetID,
+		ComplexityWeights: gas.Dimensions{
+			gas.Bandwidth: 1,
+			gas.DBRead:    1,
+			gas.DBWrite:   1,
+			gas.Compute:   1,
+		},
+		GasPrice: gasPrice,
+	}, ids.GenerateTestShortID())
+}
+
+func TestFeeEstimatorSeparatesFeeAndBalance(t *testing.T) {
+	estimator := testFeeEstimator(1)
+	owner := &secp256k1fx.OutputOwners{Threshold: 1, Addrs: []ids.ShortID{ids.GenerateTestShortID()}}
+
+	estimates := map[string]func() (FeeEstimate, error){
+		"create subnet": func() (FeeEstimate, error) { return estimator.CreateSubnet(owner) },
+		"create chain": func() (FeeEstimate, error) {
+			return estimator.CreateChain(owner, []byte("{}"), ids.GenerateTestID(), "chain")
+		},
+		"convert": func() (FeeEstimate, error) {
+			return estimator.ConvertSubnetToL1(owner, make([]byte, 20), []uint64{units.Avax, 2 * units.Avax})
+		},
+		"register":         func() (FeeEstimate, error) { return estimator.RegisterL1Validator(3*units.Avax, 5) },
+		"set weight":       func() (FeeEstimate, error) { return estimator.SetL1ValidatorWeight(5) },
+		"increase balance": func() (FeeEstimate, error) { return estimator.IncreaseL1ValidatorBalance(4 * units.Avax) },
+		"disable":          func() (FeeEstimate, error) { return estimator.DisableL1Validator(owner) },
+	}
+	balances := map[string]uint64{
+		"convert":          3 * units.Avax,
+		"register":         3 * units.Avax,
+		"increase balance": 4 * units.Avax,
+	}
+	for name, estimateFn := range estimates {
+		t.Run(name, func(t *testing.T) {
+			estimate, err := estimateFn()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if estimate.Fee == 0 {
+				t.Fatal("fee is 0")
+			}
+			// At a gas price of 1 the fee is the gas, far below a milliAVAX
+			if estimate.Fee > units.MilliAvax {
+				t.Fatalf("fee %d includes more than the gas", estimate.Fee)
+			}
+			if estimate.Balance != balances[name] {
+				t.Fatalf("balance is %d, want %d", estimate.Balance, balances[name])
+			}
+			if estimate.Total() != estimate.Fee+estimate.Balance {
+				t.Fatalf("total %d isn't fee %d plus balance %d", estimate.Total(), estimate.Fee, estimate.Balance)
+			}
+		})
+	}
+}
+
+func TestFeeEstimatorScales(t *testing.T) {
+	cheap, err := testFeeEstimator(1).SetL1ValidatorWeight(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expensive, err := testFeeEstimator(10).SetL1ValidatorWeight(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expensive.Fee != 10*cheap.Fee {
+		t.Fatalf("fee at 10x the gas price is %d, want %d", expensive.Fee, 10*cheap.Fee)
+	}
+
+	estimator := testFeeEstimator(1)
+	owner := &secp256k1fx.OutputOwners{Threshold: 1, Addrs: []ids.ShortID{ids.GenerateTestShortID()}}
+	one, err := estimator.ConvertSubnetToL1(owner, make([]byte, 20), []uint64{units.Avax})
+	if err != nil {
+		t.Fatal(err)
+	}
+	three, err := estimator.ConvertSubnetToL1(owner, make([]byte, 20), []uint64{units.Avax, units.Avax, units.Avax})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if three.Fee <= one.Fee {
+		t.Fatalf("converting with 3 validators costs %d, not more than %d with 1", three.Fee, one.Fee)
+	}
+}
@@ -0,0 +1,159 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"gopkg.in/yaml.v3"
+)
+
+// GenesisSpec overrides the defaults of generate-genesis. Every field is
+// optional, a missing one keeps the default.
+type GenesisSpec struct {
+	// ChainID is the EVM chain ID of the L1
+	ChainID *uint64 `json:"chainID,omitempty"`
+	// FeeConfig is merged field by field over the default fee config, in the
+	// format of the subnet-evm feeConfig
+	FeeConfig json.RawMessage `json:"feeConfig,omitempty"`
+	// Alloc adds accounts to the genesis, in the format of the subnet-evm alloc
+	Alloc types.GenesisAlloc `json:"alloc,omitempty"`
+	Warp  *GenesisWarpSpec   `json:"warp,omitempty"`
+	// Precompiles enables subnet-evm precompiles by name, like txAllowList,
+	// each in the subnet-evm format of its config
+	Precompiles map[string]json.RawMessage `json:"precompiles,omitempty"`
+	// Proxy is the proxy in front of the validator manager, like
+	// oz5-transparent
+	Proxy string `json:"proxy,omitempty"`
+	// Timestamp of the genesis block in unix seconds, defaults to now
+	Timestamp *uint64 `json:"timestamp,omitempty"`
+}
+
+type GenesisWarpSpec struct {
+	QuorumNumerator              *uint64 `json:"quorumNumerator,omitempty"`
+	RequirePrimaryNetworkSigners *bool   `json:"requirePrimaryNetworkSigners,omitempty"`
+}
+
+// LoadGenesisSpec reads a spec from a .json file, or from YAML otherwise.
+// Unknown fields are rejected so that typos don't go unnoticed.
+func LoadGenesisSpec(path string) (*GenesisSpec, error) {
+	specBytes, err := LoadBytes(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading genesis spec: %w", err)
+	}
+	if filepath.Ext(path) != ".json" {
+		specBytes, err = yamlToJSON(specBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing genesis spec %s: %w", path, err)
+		}
+	}
+
+	spec := &GenesisSpec{}
+	if err := DecodeStrictJSON(specBytes, spec); err != nil {
+		return nil, fmt.Errorf("parsing genesis spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// DecodeStrictJSON unmarshals data into v, failing on unknown fields
+func DecodeStrictJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// yamlToJSON converts YAML to JSON without going through float64, so that
+// balances in wei keep every digit. Hex literals stay strings, like the EVM
+// JSON formats expect them.
+func yamlToJSON(yamlBytes []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(yamlBytes, &document); err != nil {
+		return nil, err
+	}
+	value, err := yamlNodeValue(&document)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+var yamlDecimalInteger = regexp.MustCompile(`^[-+]?[0-9_]+$`)
+
+func yamlNodeValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return map[string]interface{}{}, nil
+		}
+		return yamlNodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.MappingNode:
+		values := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			values[node.Content[i].Value] = value
+		}
+		return values, nil
+	case yaml.SequenceNode:
+		values := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			value, err := yamlNodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var value bool
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "!!int", "!!float":
+		// Integers too large for int64 are tagged as floats
+		if node.ShortTag() == "!!float" && !yamlDecimalInteger.MatchString(node.Value) {
+			value, err := strconv.ParseFloat(node.Value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid number %q", node.Line, node.Value)
+			}
+			return value, nil
+		}
+		if strings.HasPrefix(strings.ToLower(node.Value), "0x") {
+			return node.Value, nil
+		}
+		value, ok := parseInteger(strings.ReplaceAll(node.Value, "_", ""))
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid integer %q", node.Line, node.Value)
+		}
+		return json.Number(value.String()), nil
+	default:
+		return node.Value, nil
+	}
+}
+
+// parseInteger parses a decimal integer, or one with a 0x, 0o or 0b prefix.
+// A zero-padded number like 0755 is still decimal.
+func parseInteger(s string) (*big.Int, bool) {
+	base := 10
+	unsigned := strings.ToLower(strings.TrimLeft(s, "+-"))
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0o") || strings.HasPrefix(unsigned, "0b") {
+		base = 0
+	}
+	return new(big.Int).SetString(s, base)
+}
@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"small int", "a: 12", `{"a":12}`},
+		{"signed int", "a: +12", `{"a":12}`},
+		{"negative int", "a: -12", `{"a":-12}`},
+		{"wei balance beyond uint64", "a: 1_000_000_000_000_000_000_000", `{"a":1000000000000000000000}`},
+		{"beyond float64 precision", "a: 123456789012345678901234567890", `{"a":123456789012345678901234567890}`},
+		{"hex stays a string", "a: 0x1234", `{"a":"0x1234"}`},
+		{"long hex stays a string", "a: 0x00000000000000000000000000000000000000000000000000000000000000ff", `{"a":"0x00000000000000000000000000000000000000000000000000000000000000ff"}`},
+		{"zero-padded is decimal", "a: 0755", `{"a":755}`},
+		{"zero-padded with 8 and 9", "a: 089", `{"a":89}`},
+		{"long zero-padded", "a: 012345678901234567890123", `{"a":12345678901234567890123}`},
+		{"zero", "a: 0", `{"a":0}`},
+		{"octal prefix", "a: 0o17", `{"a":15}`},
+		{"binary prefix", "a: 0b101", `{"a":5}`},
+		{"float", "a: 1.5", `{"a":1.5}`},
+		{"quoted number stays a string", `a: "12"`, `{"a":"12"}`},
+		{"bool and null", "a: true\nb: null", `{"a":true,"b":null}`},
+		{"nested", "a:\n  b: [1, 0x2]", `{"a":{"b":[1,"0x2"]}}`},
+		{"alias", "a: &x 7\nb: *x", `{"a":7,"b":7}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(test.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("yamlToJSON(%q) = %s, want %s", test.yaml, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadGenesisSpec(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	addr := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	spec, err := LoadGenesisSpec(write("spec.yaml", `
+chainID: 4321
+feeConfig:
+  minBaseFee: 1
+alloc:
+  "0x00000000000000000000000000000000000000aa":
+    balance: "0x3635c9adc5dea00000"
+warp:
+  quorumNumerator: 80
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.ChainID == nil || *spec.ChainID != 4321 {
+		t.Fatalf("chain ID is %v, want 4321", spec.ChainID)
+	}
+	wantBalance, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	if account, ok := spec.Alloc[addr]; !ok || account.Balance.Cmp(wantBalance) != 0 {
+		t.Fatalf("alloc of %s is %+v, want a balance of %s", addr, spec.Alloc[addr], wantBalance)
+	}
+	if spec.Warp == nil || spec.Warp.QuorumNumerator == nil || *spec.Warp.QuorumNumerator != 80 {
+		t.Fatalf("warp is %+v, want a quorum numerator of 80", spec.Warp)
+	}
+
+	jsonSpec, err := LoadGenesisSpec(write("spec.json", `{"chainID": 4321}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jsonSpec.ChainID == nil || *jsonSpec.ChainID != 4321 {
+		t.Fatalf("chain ID of the JSON spec is %v, want 4321", jsonSpec.ChainID)
+	}
+
+	empty, err := LoadGenesisSpec(write("empty.yaml", ""))
+	if err != nil {
+		t.Fatalf("empty spec: %s", err)
+	}
+	if empty.ChainID != nil || empty.Alloc != nil || empty.Warp != nil {
+		t.Fatalf("empty spec set %+v", empty)
+	}
+
+	for name, content := range map[string]string{
+		"typo.yaml":     "chainIDs: 4321",
+		"unknown.json":  `{"chainID": 4321, "gasLimit": 1}`,
+		"negative.yaml": "chainID: -1",
+		"invalid.yaml":  "chainID: [",
+	} {
+		if _, err := LoadGenesisSpec(write(name, content)); err == nil {
+			t.Fatalf("spec %s was accepted: %s", name, content)
+		}
+	}
+}
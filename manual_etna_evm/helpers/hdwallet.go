@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/term"
+)
+
+// MnemonicEnvVar imports a BIP-39 phrase without a prompt
+const MnemonicEnvVar = "ETNA_MNEMONIC"
+
+const (
+	// AvalancheCoinType is the BIP-44 coin type of the X and P chains, as used by Core and the Ledger app
+	AvalancheCoinType = 9000
+	// EthereumCoinType is the BIP-44 coin type of EVM accounts, so derived keys match MetaMask
+	EthereumCoinType = 60
+
+	mnemonicEntropyBits = 256
+)
+
+// HDKeyRole is a key derived from the workspace mnemonic. Keys used on the
+// P-chain only follow the Avalanche path, keys that sign EVM txs follow the
+// Ethereum path.
+type HDKeyRole struct {
+	Name     string
+	CoinType uint32
+	Index    uint32
+}
+
+// Path is the BIP-44 path of the role, m/44'/coin'/0'/0/index
+func (r HDKeyRole) Path() string {
+	return fmt.Sprintf("m/44'/%d'/0'/0/%d", r.CoinType, r.Index)
+}
+
+// OwnerKeyRole deploys the validator manager and pays L1 gas, so it is an EVM
+// account. It can't stand in for the P-chain roles of a phrase, whose keys are
+// derived from Role.HD.
+var OwnerKeyRole = HDKeyRole{Name: "validator manager owner", CoinType: EthereumCoinType, Index: 0}
+
+// NewMnemonic creates a 24 word BIP-39 phrase
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", fmt.Errorf("generating entropy: %w", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// ReadMnemonic returns the phrase to import from the environment or a prompt,
+// or "" if the user wants a new one
+func ReadMnemonic() (string, error) {
+	mnemonic, ok := os.LookupEnv(MnemonicEnvVar)
+	if !ok {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", nil
+		}
+		var err error
+		mnemonic, err = readPassphrase("Mnemonic to import (leave empty to create a new one): ")
+		if err != nil {
+			return "", err
+		}
+	}
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if mnemonic != "" && !bip39.IsMnemonicValid(mnemonic) {
+		return "", errors.New("invalid BIP-39 mnemonic")
+	}
+	return mnemonic, nil
+}
+
+// DeriveHDKey derives the key of role from a BIP-39 phrase without passphrase
+func DeriveHDKey(mnemonic string, role HDKeyRole) (*secp256k1.PrivateKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	key, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("deriving master key: %w", err)
+	}
+	path := []uint32{
+		bip32.FirstHardenedChild + 44,
+		bip32.FirstHardenedChild + role.CoinType,
+		bip32.FirstHardenedChild,
+		0,
+		role.Index,
+	}
+	for _, index := range path {
+		key, err = key.NewChildKey(index)
+		if err != nil {
+			return nil, fmt.Errorf("deriving %s: %w", role.Path(), err)
+		}
+	}
+	return secp256k1.ToPrivateKey(key.Key)
+}
@@ -0,0 +1,248 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+// KeystorePassphraseEnvVar unlocks encrypted keystores without a prompt
+const KeystorePassphraseEnvVar = "ETNA_KEYSTORE_PASSPHRASE"
+
+// KeyProvider stores and loads a secp256k1 key, whatever the format on disk
+type KeyProvider interface {
+	Exists() (bool, error)
+	Load() (*secp256k1.PrivateKey, error)
+	Save(key *secp256k1.PrivateKey) error
+	// Account returns the addresses of the key, without asking for a
+	// passphrase when the file records them
+	Account() (SignerAccount, error)
+	// Path is the file holding the key
+	Path() string
+}
+
+// OwnerKeyProvider returns the provider of the validator manager owner key of
+// the current workspace. An encrypted keystore takes precedence over a
+// plaintext key file.
+func OwnerKeyProvider() KeyProvider {
+	encrypted := NewKeystoreKeyProvider(ValidatorManagerOwnerKeystorePath)
+	if exists, err := encrypted.Exists(); err == nil && exists {
+		return encrypted
+	}
+	return NewPlaintextKeyProvider(ValidatorManagerOwnerKeyPath)
+}
+
+func LoadOwnerKey() (*secp256k1.PrivateKey, error) {
+	return OwnerKeyProvider().Load()
+}
+
+// plaintextKeyProvider keeps the key as a hex string, readable by its owner only
+type plaintextKeyProvider struct {
+	path string
+}
+
+func NewPlaintextKeyProvider(path string) KeyProvider {
+	return &plaintextKeyProvider{path: path}
+}
+
+func (p *plaintextKeyProvider) Exists() (bool, error) {
+	return FileExists(p.path)
+}
+
+func (p *plaintextKeyProvider) Load() (*secp256k1.PrivateKey, error) {
+	return LoadSecp256k1PrivateKey(p.path)
+}
+
+func (p *plaintextKeyProvider) Save(key *secp256k1.PrivateKey) error {
+	return SaveSecp256k1PrivateKey(p.path, key)
+}
+
+func (p *plaintextKeyProvider) Account() (SignerAccount, error) {
+	key, err := p.Load()
+	if err != nil {
+		return SignerAccount{}, err
+	}
+	signer := NewLocalSigner(key)
+	return SignerAccount{Address: signer.Address(), EthAddress: signer.EthAddress()}, nil
+}
+
+func (p *plaintextKeyProvider) Path() string {
+	return p.path
+}
+
+// keystoreKeyProvider keeps the key in the scrypt encrypted go-ethereum
+// keystore format, so the file can be imported by geth, clef or MetaMask
+type keystoreKeyProvider struct {
+	path string
+}
+
+// keystoreAddresses are the plaintext fields of a keystore. The go-ethereum
+// format only records the EVM address, avalancheAddress is added next to it.
+type keystoreAddresses struct {
+	Address          string       `json:"address"`
+	AvalancheAddress *ids.ShortID `json:"avalancheAddress,omitempty"`
+}
+
+func NewKeystoreKeyProvider(path string) KeyProvider {
+	return &keystoreKeyProvider{path: path}
+}
+
+func (p *keystoreKeyProvider) Exists() (bool, error) {
+	return FileExists(p.path)
+}
+
+func (p *keystoreKeyProvider) Load() (*secp256k1.PrivateKey, error) {
+	keyJSON, err := LoadBytes(p.path)
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := keystorePassphrase(p.path, false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting keystore %s: %w", p.path, err)
+	}
+	cachePassphrase(p.path, passphrase)
+	return secp256k1.ToPrivateKey(key.PrivateKey.D.FillBytes(make([]byte, secp256k1.PrivateKeyLen)))
+}
+
+func (p *keystoreKeyProvider) Save(key *secp256k1.PrivateKey) error {
+	passphrase, err := keystorePassphrase(p.path, true)
+	if err != nil {
+		return err
+	}
+	ecdsaKey := key.ToECDSA()
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("generating keystore id: %w", err)
+	}
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(ecdsaKey.PublicKey),
+		PrivateKey: ecdsaKey,
+	}, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return fmt.Errorf("encrypting key: %w", err)
+	}
+	keyJSON, err = addAvalancheAddress(keyJSON, key.Address())
+	if err != nil {
+		return err
+	}
+	if err := SavePrivateBytes(p.path, keyJSON); err != nil {
+		return err
+	}
+	cachePassphrase(p.path, passphrase)
+	return nil
+}
+
+// Account reads the addresses from the plaintext fields of the keystore.
+// Keystores written by other tools have no avalancheAddress and are decrypted.
+func (p *keystoreKeyProvider) Account() (SignerAccount, error) {
+	keyJSON, err := LoadBytes(p.path)
+	if err != nil {
+		return SignerAccount{}, err
+	}
+	addresses := keystoreAddresses{}
+	if err := json.Unmarshal(keyJSON, &addresses); err != nil {
+		return SignerAccount{}, fmt.Errorf("parsing keystore %s: %w", p.path, err)
+	}
+	if addresses.AvalancheAddress == nil || !common.IsHexAddress(addresses.Address) {
+		key, err := p.Load()
+		if err != nil {
+			return SignerAccount{}, err
+		}
+		signer := NewLocalSigner(key)
+		return SignerAccount{Address: signer.Address(), EthAddress: signer.EthAddress()}, nil
+	}
+	return SignerAccount{Address: *addresses.AvalancheAddress, EthAddress: common.HexToAddress(addresses.Address)}, nil
+}
+
+func (p *keystoreKeyProvider) Path() string {
+	return p.path
+}
+
+// addAvalancheAddress records the P-chain address of the key in keyJSON, so
+// Account doesn't need the passphrase
+func addAvalancheAddress(keyJSON []byte, address ids.ShortID) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(keyJSON, &fields); err != nil {
+		return nil, fmt.Errorf("parsing keystore: %w", err)
+	}
+	addressJSON, err := json.Marshal(address)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling address: %w", err)
+	}
+	fields["avalancheAddress"] = addressJSON
+	keyJSON, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling keystore: %w", err)
+	}
+	return keyJSON, nil
+}
+
+// The passphrase of each keystore is asked for at most once per run, and
+// only remembered once it decrypted or encrypted that keystore
+var cachedPassphrases = map[string]string{}
+
+func cachePassphrase(path string, passphrase string) {
+	cachedPassphrases[filepath.Clean(path)] = passphrase
+}
+
+// keystorePassphrase returns the passphrase of the keystore at path. With
+// confirm the keystore is being created, so a terminal prompt is always
+// repeated and nothing cached is reused.
+func keystorePassphrase(path string, confirm bool) (string, error) {
+	if passphrase, ok := cachedPassphrases[filepath.Clean(path)]; ok && !confirm {
+		return passphrase, nil
+	}
+	if passphrase, ok := os.LookupEnv(KeystorePassphraseEnvVar); ok {
+		if confirm && passphrase == "" {
+			return "", fmt.Errorf("passphrase can't be empty, %s is set but empty", KeystorePassphraseEnvVar)
+		}
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("keystore %s is encrypted, set %s or run from a terminal", path, KeystorePassphraseEnvVar)
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Passphrase for %s: ", path))
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		if passphrase == "" {
+			return "", errors.New("passphrase can't be empty")
+		}
+		repeated, err := readPassphrase("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if repeated != passphrase {
+			return "", errors.New("passphrases don't match")
+		}
+	}
+	return passphrase, nil
+}
+
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	return strings.TrimRight(string(passphrase), "\r\n"), nil
+}
@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+)
+
+func TestKeystorePassphrasePerPath(t *testing.T) {
+	t.Cleanup(func() { cachedPassphrases = map[string]string{} })
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "first.json"), filepath.Join(dir, "second.json")}
+	passphrases := []string{"first passphrase", "second passphrase"}
+	keys := make([]*secp256k1.PrivateKey, len(paths))
+
+	for i, path := range paths {
+		t.Setenv(KeystorePassphraseEnvVar, passphrases[i])
+		key, err := secp256k1.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := NewKeystoreKeyProvider(path).Save(key); err != nil {
+			t.Fatalf("saving %s: %s", path, err)
+		}
+		keys[i] = key
+	}
+
+	// Without the variable, each keystore is unlocked by the passphrase it
+	// was saved with
+	os.Unsetenv(KeystorePassphraseEnvVar)
+	for i, path := range paths {
+		if got := cachedPassphrases[path]; got != passphrases[i] {
+			t.Fatalf("cached passphrase of %s is %q, want %q", path, got, passphrases[i])
+		}
+		key, err := NewKeystoreKeyProvider(path).Load()
+		if err != nil {
+			t.Fatalf("loading %s: %s", path, err)
+		}
+		if key.Address() != keys[i].Address() {
+			t.Fatalf("%s holds %s, want %s", path, key.Address(), keys[i].Address())
+		}
+	}
+}
+
+func TestKeystoreWrongPassphraseIsNotCached(t *testing.T) {
+	t.Cleanup(func() { cachedPassphrases = map[string]string{} })
+	path := filepath.Join(t.TempDir(), "key.json")
+	t.Setenv(KeystorePassphraseEnvVar, "right")
+	key, err := secp256k1.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewKeystoreKeyProvider(path).Save(key); err != nil {
+		t.Fatal(err)
+	}
+	delete(cachedPassphrases, path)
+
+	t.Setenv(KeystorePassphraseEnvVar, "wrong")
+	if _, err := NewKeystoreKeyProvider(path).Load(); err == nil {
+		t.Fatal("loaded the keystore with the wrong passphrase")
+	}
+	if _, ok := cachedPassphrases[path]; ok {
+		t.Fatal("the wrong passphrase was cached")
+	}
+}
+
+func TestKeystoreAccountWithoutPassphrase(t *testing.T) {
+	t.Cleanup(func() { cachedPassphrases = map[string]string{} })
+	path := filepath.Join(t.TempDir(), "key.json")
+	t.Setenv(KeystorePassphraseEnvVar, "passphrase")
+	key, err := secp256k1.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := NewKeystoreKeyProvider(path)
+	if err := provider.Save(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a cached or exported passphrase, and no terminal, only the
+	// plaintext addresses can be read
+	delete(cachedPassphrases, path)
+	os.Unsetenv(KeystorePassphraseEnvVar)
+	account, err := provider.Account()
+	if err != nil {
+		t.Fatalf("reading the account asked for the passphrase: %s", err)
+	}
+	signer := NewLocalSigner(key)
+	if account.Address != signer.Address() || account.EthAddress != signer.EthAddress() {
+		t.Fatalf("account is %+v, want %s and %s", account, signer.Address(), signer.EthAddress())
+	}
+
+	// The added field doesn't stop the keystore from decrypting
+	t.Setenv(KeystorePassphraseEnvVar, "passphrase")
+	loaded, err := provider.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Address() != key.Address() {
+		t.Fatalf("keystore holds %s, want %s", loaded.Address(), key.Address())
+	}
+}
+
+func TestKeystoreRejectsEmptyEnvPassphrase(t *testing.T) {
+	t.Cleanup(func() { cachedPassphrases = map[string]string{} })
+	path := filepath.Join(t.TempDir(), "key.json")
+	t.Setenv(KeystorePassphraseEnvVar, "")
+	key, err := secp256k1.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewKeystoreKeyProvider(path).Save(key); err == nil {
+		t.Fatal("encrypted a keystore with an empty passphrase")
+	}
+	if exists, err := FileExists(path); err != nil || exists {
+		t.Fatalf("the keystore was written (%v)", err)
+	}
+}
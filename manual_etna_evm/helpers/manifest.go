@@ -0,0 +1,369 @@
+package helpers
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-cli/pkg/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/etna-devnet-resources/manual_etna_evm/config"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ManifestSchemaVersion is bumped every time the manifest layout changes in a
+// way older binaries can't read. Older manifests are upgraded on load.
+const ManifestSchemaVersion = 1
+
+const manifestFileName = "workspace.json"
+
+// Manifest records every artifact the pipeline creates for one L1
+type Manifest struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	BaseHTTPPort  int       `json:"baseHTTPPort,omitempty"`
+
+	SubnetID ids.ID `json:"subnetID"`
+	ChainID  ids.ID `json:"chainID"`
+	// ConversionTxID is the ConvertSubnetToL1Tx, ConversionID the hash of
+	// ConversionData the P-chain reports for the converted subnet
+	ConversionTxID ids.ID          `json:"conversionTxID"`
+	ConversionID   ids.ID          `json:"conversionID"`
+	ConversionData *ConversionData `json:"conversionData,omitempty"`
+
+	ValidatorType                  string         `json:"validatorType,omitempty"`
+	ManagerAddress                 common.Address `json:"managerAddress"`
+	ExampleRewardCalculatorAddress common.Address `json:"exampleRewardCalculatorAddress"`
+	InitializeValidatorSetTx       string         `json:"initializeValidatorSetTx,omitempty"`
+	// ManagerInGenesis is set when the genesis embeds the ValidatorType
+	// implementation, so deploy-validator-manager has nothing to deploy
+	ManagerInGenesis bool `json:"managerInGenesis,omitempty"`
+
+	Validators []ManifestValidator `json:"validators"`
+
+	// Roles holds the address of each role given to someone without a key in
+	// this workspace, by role name
+	Roles map[string]string `json:"roles,omitempty"`
+
+	Steps map[string]*StepStatus `json:"steps,omitempty"`
+}
+
+// ManifestValidator is an L1 validator registered by this tool
+type ManifestValidator struct {
+	NodeID       ids.NodeID `json:"nodeID"`
+	ValidationID ids.ID     `json:"validationID"`
+	Weight       uint64     `json:"weight"`
+	// Balance is what the validator was given on the P-chain to pay for
+	// its validation, as of its registration
+	Balance     uint64 `json:"balance,omitempty"`
+	CredsFolder string `json:"credsFolder,omitempty"`
+	// NodeURI is where a validator run outside this workspace answers
+	NodeURI   string `json:"nodeURI,omitempty"`
+	Bootstrap bool   `json:"bootstrap,omitempty"`
+	Removed   bool   `json:"removed,omitempty"`
+	// Disabled validators were deactivated on the P-chain by their disable owner
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+const (
+	StepPending = "pending"
+	StepRunning = "running"
+	StepDone    = "done"
+	StepFailed  = "failed"
+)
+
+// StepStatus is the last run of one `up` pipeline step. Inputs is a digest of
+// the outputs of the steps it depends on at the time it ran.
+type StepStatus struct {
+	Status     string            `json:"status"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Inputs     string            `json:"inputs,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+}
+
+// Step returns the recorded status of a pipeline step, pending if it never ran
+func (m *Manifest) Step(name string) StepStatus {
+	if step, ok := m.Steps[name]; ok && step != nil {
+		return *step
+	}
+	return StepStatus{Status: StepPending}
+}
+
+// SetStep records the status of a pipeline step
+func (m *Manifest) SetStep(name string, step StepStatus) {
+	if m.Steps == nil {
+		m.Steps = map[string]*StepStatus{}
+	}
+	m.Steps[name] = &step
+}
+
+// NodeBaseHTTPPort is the HTTP port of node0, further nodes use the ports after it
+func (m *Manifest) NodeBaseHTTPPort() int {
+	if m.BaseHTTPPort == 0 {
+		return DefaultNodeHTTPPort
+	}
+	return m.BaseHTTPPort
+}
+
+// Validator returns the validator with the given node ID, if it was recorded
+func (m *Manifest) Validator(nodeID ids.NodeID) (ManifestValidator, bool) {
+	for _, validator := range m.Validators {
+		if validator.NodeID == nodeID {
+			return validator, true
+		}
+	}
+	return ManifestValidator{}, false
+}
+
+// SetValidator inserts or replaces the validator with the same node ID
+func (m *Manifest) SetValidator(validator ManifestValidator) {
+	for i := range m.Validators {
+		if m.Validators[i].NodeID == validator.NodeID {
+			m.Validators[i] = validator
+			return
+		}
+	}
+	m.Validators = append(m.Validators, validator)
+}
+
+// LoadManifest reads the workspace manifest. If there is none yet, files left
+// by older versions of this tool are imported, otherwise an empty manifest is
+// returned.
+func LoadManifest() (*Manifest, error) {
+	exists, err := FileExists(ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return migrateLegacyData(filepath.Dir(ManifestPath))
+	}
+	return readManifest(ManifestPath)
+}
+
+// SaveManifest atomically replaces the workspace manifest
+func SaveManifest(manifest *Manifest) error {
+	return writeManifest(ManifestPath, manifest)
+}
+
+func readManifest(path string) (*Manifest, error) {
+	manifest := &Manifest{SchemaVersion: ManifestSchemaVersion, Validators: []ManifestValidator{}}
+	exists, err := FileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return manifest, nil
+	}
+
+	manifestBytes, err := LoadBytes(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+	if manifest.SchemaVersion > ManifestSchemaVersion {
+		return nil, fmt.Errorf("manifest %s has schema version %d, this binary only supports up to %d", path, manifest.SchemaVersion, ManifestSchemaVersion)
+	}
+	manifest.SchemaVersion = ManifestSchemaVersion
+	return manifest, nil
+}
+
+func writeManifest(path string, manifest *Manifest) error {
+	manifest.SchemaVersion = ManifestSchemaVersion
+	manifest.UpdatedAt = time.Now().UTC()
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling manifest: %w", err)
+	}
+	return SaveBytesAtomic(path, manifestBytes)
+}
+
+// UpdateManifest loads the manifest, applies update and saves the result.
+// Nothing is written if update returns an error.
+func UpdateManifest(update func(manifest *Manifest) error) error {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	if err := update(manifest); err != nil {
+		return err
+	}
+	return SaveManifest(manifest)
+}
+
+func LoadSubnetID() (ids.ID, error) {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return ids.Empty, err
+	}
+	if manifest.SubnetID == ids.Empty {
+		return ids.Empty, errors.New("subnet ID is not recorded in the manifest, run create-subnet first")
+	}
+	return manifest.SubnetID, nil
+}
+
+func LoadChainID() (ids.ID, error) {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return ids.Empty, err
+	}
+	if manifest.ChainID == ids.Empty {
+		return ids.Empty, errors.New("chain ID is not recorded in the manifest, run create-chain first")
+	}
+	return manifest.ChainID, nil
+}
+
+// Files written by versions of this tool before the manifest existed
+const (
+	legacySubnetIdPath                   = "subnet_id.txt"
+	legacyChainIdPath                    = "chain_id.txt"
+	legacyConversionIdPath               = "conversion_id.txt"
+	legacyInitializeValidatorSetTxPath   = "initialize_validator_set_tx.txt"
+	legacyExampleRewardCalculatorAddress = "example_reward_calculator_address.txt"
+	addValidatorFolderPrefix             = "add_validator_"
+)
+
+// migrateLegacyData builds a manifest out of the per-artifact files in
+// dataDir. The manifest is only written if at least one artifact was found;
+// the legacy files are left in place.
+func migrateLegacyData(dataDir string) (*Manifest, error) {
+	manifest := &Manifest{SchemaVersion: ManifestSchemaVersion, Validators: []ManifestValidator{}}
+	imported := []string{}
+
+	idFiles := []struct {
+		name   string
+		target *ids.ID
+	}{
+		{legacySubnetIdPath, &manifest.SubnetID},
+		{legacyChainIdPath, &manifest.ChainID},
+		{legacyConversionIdPath, &manifest.ConversionTxID},
+	}
+	for _, idFile := range idFiles {
+		path := filepath.Join(dataDir, idFile.name)
+		exists, err := FileExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		id, err := LoadId(path)
+		if err != nil {
+			return nil, fmt.Errorf("migrating %s: %w", path, err)
+		}
+		*idFile.target = id
+		imported = append(imported, path)
+	}
+
+	txPath := filepath.Join(dataDir, legacyInitializeValidatorSetTxPath)
+	exists, err := FileExists(txPath)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		manifest.InitializeValidatorSetTx, err = LoadText(txPath)
+		if err != nil {
+			return nil, fmt.Errorf("migrating %s: %w", txPath, err)
+		}
+		imported = append(imported, txPath)
+	}
+
+	rewardCalculatorPath := filepath.Join(dataDir, legacyExampleRewardCalculatorAddress)
+	exists, err = FileExists(rewardCalculatorPath)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		manifest.ExampleRewardCalculatorAddress, err = LoadAddress(rewardCalculatorPath)
+		if err != nil {
+			return nil, fmt.Errorf("migrating %s: %w", rewardCalculatorPath, err)
+		}
+		imported = append(imported, rewardCalculatorPath)
+	}
+
+	if manifest.ConversionTxID != ids.Empty {
+		manifest.ManagerAddress = common.HexToAddress(config.ProxyContractAddress)
+
+		// The pipeline always converted with node0 as the only bootstrap validator
+		bootstrapFolder := filepath.Join(dataDir, "node0", "staking")
+		nodeID, err := nodeIDFromCredsFolder(bootstrapFolder)
+		if err == nil {
+			manifest.SetValidator(ManifestValidator{
+				NodeID:       nodeID,
+				ValidationID: manifest.SubnetID.Append(0),
+				Weight:       constants.BootstrapValidatorWeight,
+				CredsFolder:  bootstrapFolder + "/",
+				Bootstrap:    true,
+			})
+		}
+	}
+
+	entries, err := os.ReadDir(dataDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", dataDir, err)
+	}
+	addValidatorFolders := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), addValidatorFolderPrefix) {
+			addValidatorFolders = append(addValidatorFolders, entry.Name())
+		}
+	}
+	sort.Slice(addValidatorFolders, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(addValidatorFolders[i], addValidatorFolderPrefix))
+		b, _ := strconv.Atoi(strings.TrimPrefix(addValidatorFolders[j], addValidatorFolderPrefix))
+		return a < b
+	})
+	for _, folderName := range addValidatorFolders {
+		folder := filepath.Join(dataDir, folderName)
+		nodeID, err := nodeIDFromCredsFolder(folder)
+		if err != nil {
+			log.Printf("Skipping %s during migration: %s\n", folder, err)
+			continue
+		}
+		// Validation IDs were never persisted, they are filled in the next time the validator is touched
+		manifest.SetValidator(ManifestValidator{
+			NodeID:      nodeID,
+			Weight:      constants.NonBootstrapValidatorWeight,
+			CredsFolder: folder + "/",
+		})
+		imported = append(imported, folder)
+	}
+
+	if len(imported) == 0 {
+		return manifest, nil
+	}
+
+	if err := SaveManifest(manifest); err != nil {
+		return nil, err
+	}
+	log.Printf("Migrated %d legacy files into %s: %s\n", len(imported), ManifestPath, strings.Join(imported, ", "))
+	return manifest, nil
+}
+
+func nodeIDFromCredsFolder(folder string) (ids.NodeID, error) {
+	certBytes, err := LoadBytes(filepath.Join(folder, "staker.crt"))
+	if err != nil {
+		return ids.NodeID{}, err
+	}
+	block, _ := pem.Decode(certBytes)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return ids.NodeID{}, fmt.Errorf("failed to decode PEM block containing certificate in %s", folder)
+	}
+	cert, err := staking.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ids.NodeID{}, fmt.Errorf("parsing certificate in %s: %w", folder, err)
+	}
+	return ids.NodeIDFromCert(cert), nil
+}
@@ -0,0 +1,17 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadManifestRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), manifestFileName)
+	if err := os.WriteFile(path, []byte(`{"schemaVersion": 99}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readManifest(path); err == nil {
+		t.Fatal("read a manifest from a newer binary")
+	}
+}
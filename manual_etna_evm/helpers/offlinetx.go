@@ -0,0 +1,336 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+	psigner "github.com/ava-labs/avalanchego/wallet/chain/p/signer"
+	pwallet "github.com/ava-labs/avalanchego/wallet/chain/p/wallet"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+)
+
+// PChainWallet is the P-chain part of primary.MakeWallet. It keeps the backend
+// around, so the UTXOs and owners a tx spends can be saved with it for signing
+// on another machine.
+type PChainWallet struct {
+	pwallet.Wallet
+	Backend   pwallet.Backend
+	NetworkID uint32
+}
+
+// NewPChainWallet fetches the UTXOs of kc and the owners of subnetIDs and
+// validationIDs from uri
+func NewPChainWallet(ctx context.Context, uri string, kc keychain.Keychain, subnetIDs []ids.ID, validationIDs []ids.ID) (*PChainWallet, error) {
+	addrs := kc.Addresses()
+	state, err := primary.FetchState(ctx, uri, addrs)
+	if err != nil {
+		return nil, fmt.Errorf("fetching wallet state: %w", err)
+	}
+	subnetOwners, err := platformvm.GetSubnetOwners(state.PClient, ctx, subnetIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching subnet owners: %w", err)
+	}
+	deactivationOwners, err := platformvm.GetDeactivationOwners(state.PClient, ctx, validationIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching deactivation owners: %w", err)
+	}
+	owners := make(map[ids.ID]fx.Owner, len(subnetOwners)+len(deactivationOwners))
+	for id, owner := range subnetOwners {
+		owners[id] = owner
+	}
+	for id, owner := range deactivationOwners {
+		owners[id] = owner
+	}
+
+	backend := pwallet.NewBackend(state.PCTX, common.NewChainUTXOs(constants.PlatformChainID, state.UTXOs), owners)
+	return &PChainWallet{
+		Wallet: pwallet.New(
+			p.NewClient(state.PClient, backend),
+			pbuilder.New(addrs, state.PCTX, backend),
+			psigner.New(kc, backend),
+		),
+		Backend:   backend,
+		NetworkID: state.PCTX.NetworkID,
+	}, nil
+}
+
+// AddressKeychain knows addresses without their keys. A wallet built on it
+// picks the UTXOs of keys kept on another machine but can't sign anything.
+type AddressKeychain struct {
+	addrs set.Set[ids.ShortID]
+}
+
+func NewAddressKeychain(addrs ...ids.ShortID) *AddressKeychain {
+	return &AddressKeychain{addrs: set.Of(addrs...)}
+}
+
+func (kc *AddressKeychain) Get(ids.ShortID) (keychain.Signer, bool) {
+	return nil, false
+}
+
+func (kc *AddressKeychain) Addresses() set.Set[ids.ShortID] {
+	return kc.addrs
+}
+
+// OfflineTx is a P-chain tx, signed or not, saved with the UTXOs and owners
+// the signer has to look up. Sign needs nothing else, so the keys paying for
+// and authorizing the tx can stay on a machine without network access.
+type OfflineTx struct {
+	networkID uint32
+	tx        *txs.Tx
+	utxos     map[ids.ID]*avax.UTXO
+	owners    map[ids.ID]fx.Owner
+}
+
+// offlineTxFile is the JSON layout of an OfflineTx, every field being hex
+// encoded with the P-chain codec
+type offlineTxFile struct {
+	NetworkID uint32            `json:"networkID"`
+	Tx        string            `json:"tx"`
+	UTXOs     []string          `json:"utxos"`
+	Owners    map[ids.ID]string `json:"owners,omitempty"`
+}
+
+// NewOfflineTx records everything from backend that tx needs to be signed
+func NewOfflineTx(ctx context.Context, networkID uint32, tx *txs.Tx, backend psigner.Backend) (*OfflineTx, error) {
+	if tx.Bytes() == nil {
+		if err := tx.Initialize(txs.Codec); err != nil {
+			return nil, fmt.Errorf("initializing tx: %w", err)
+		}
+	}
+	recorder := &recordingBackend{
+		backend: backend,
+		utxos:   map[ids.ID]*avax.UTXO{},
+		owners:  map[ids.ID]fx.Owner{},
+	}
+	// Signing with no keys visits every input and owner without changing the tx
+	probe, err := txs.Parse(txs.Codec, tx.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("copying tx: %w", err)
+	}
+	if err := psigner.New(NewAddressKeychain(), recorder).Sign(ctx, probe); err != nil {
+		return nil, fmt.Errorf("collecting signing context: %w", err)
+	}
+	return &OfflineTx{
+		networkID: networkID,
+		tx:        tx,
+		utxos:     recorder.utxos,
+		owners:    recorder.owners,
+	}, nil
+}
+
+func LoadOfflineTx(path string) (*OfflineTx, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var file offlineTxFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	txBytes, err := formatting.Decode(formatting.Hex, file.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("decoding tx: %w", err)
+	}
+	tx, err := txs.Parse(txs.Codec, txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing tx: %w", err)
+	}
+	offlineTx := &OfflineTx{
+		networkID: file.NetworkID,
+		tx:        tx,
+		utxos:     make(map[ids.ID]*avax.UTXO, len(file.UTXOs)),
+		owners:    make(map[ids.ID]fx.Owner, len(file.Owners)),
+	}
+	for _, encoded := range file.UTXOs {
+		utxoBytes, err := formatting.Decode(formatting.Hex, encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decoding UTXO: %w", err)
+		}
+		utxo := &avax.UTXO{}
+		if _, err := txs.Codec.Unmarshal(utxoBytes, utxo); err != nil {
+			return nil, fmt.Errorf("parsing UTXO: %w", err)
+		}
+		offlineTx.utxos[utxo.InputID()] = utxo
+	}
+	for id, encoded := range file.Owners {
+		ownerBytes, err := formatting.Decode(formatting.Hex, encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decoding owner of %s: %w", id, err)
+		}
+		var owner fx.Owner
+		if _, err := txs.Codec.Unmarshal(ownerBytes, &owner); err != nil {
+			return nil, fmt.Errorf("parsing owner of %s: %w", id, err)
+		}
+		offlineTx.owners[id] = owner
+	}
+	return offlineTx, nil
+}
+
+func (o *OfflineTx) Save(path string) error {
+	encodedTx, err := formatting.Encode(formatting.Hex, o.tx.Bytes())
+	if err != nil {
+		return fmt.Errorf("encoding tx: %w", err)
+	}
+	file := offlineTxFile{
+		NetworkID: o.networkID,
+		Tx:        encodedTx,
+		UTXOs:     make([]string, 0, len(o.utxos)),
+		Owners:    make(map[ids.ID]string, len(o.owners)),
+	}
+	for _, utxo := range o.utxos {
+		utxoBytes, err := txs.Codec.Marshal(txs.CodecVersion, utxo)
+		if err != nil {
+			return fmt.Errorf("encoding UTXO %s: %w", utxo.InputID(), err)
+		}
+		encoded, err := formatting.Encode(formatting.Hex, utxoBytes)
+		if err != nil {
+			return fmt.Errorf("encoding UTXO %s: %w", utxo.InputID(), err)
+		}
+		file.UTXOs = append(file.UTXOs, encoded)
+	}
+	// Keep the file stable between saves
+	slices.Sort(file.UTXOs)
+	for id, owner := range o.owners {
+		ownerBytes, err := txs.Codec.Marshal(txs.CodecVersion, &owner)
+		if err != nil {
+			return fmt.Errorf("encoding owner of %s: %w", id, err)
+		}
+		encoded, err := formatting.Encode(formatting.Hex, ownerBytes)
+		if err != nil {
+			return fmt.Errorf("encoding owner of %s: %w", id, err)
+		}
+		file.Owners[id] = encoded
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+func (o *OfflineTx) NetworkID() uint32 {
+	return o.networkID
+}
+
+// Tx is the tx with the signatures collected so far
+func (o *OfflineTx) Tx() *txs.Tx {
+	return o.tx
+}
+
+// Sign adds the signatures kc can provide, keeping the ones already there
+func (o *OfflineTx) Sign(ctx context.Context, kc keychain.Keychain) error {
+	backend := &offlineBackend{utxos: o.utxos, owners: o.owners}
+	return psigner.New(kc, backend).Sign(ctx, o.tx)
+}
+
+// RemainingSigners lists the addresses whose signature the tx still misses
+func (o *OfflineTx) RemainingSigners(ctx context.Context) ([]ids.ShortID, error) {
+	probe, err := txs.Parse(txs.Codec, o.tx.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("copying tx: %w", err)
+	}
+	// The signer only asks for signatures it doesn't have yet
+	kc := &probeKeychain{}
+	backend := &offlineBackend{utxos: o.utxos, owners: o.owners}
+	if err := psigner.New(kc, backend).Sign(ctx, probe); err != nil {
+		return nil, fmt.Errorf("checking signatures: %w", err)
+	}
+	return kc.requested.List(), nil
+}
+
+type recordingBackend struct {
+	backend psigner.Backend
+	utxos   map[ids.ID]*avax.UTXO
+	owners  map[ids.ID]fx.Owner
+}
+
+func (b *recordingBackend) GetUTXO(ctx context.Context, chainID, utxoID ids.ID) (*avax.UTXO, error) {
+	utxo, err := b.backend.GetUTXO(ctx, chainID, utxoID)
+	if err == nil {
+		b.utxos[utxoID] = utxo
+	}
+	return utxo, err
+}
+
+func (b *recordingBackend) GetOwner(ctx context.Context, ownerID ids.ID) (fx.Owner, error) {
+	owner, err := b.backend.GetOwner(ctx, ownerID)
+	if err == nil {
+		b.owners[ownerID] = owner
+	}
+	return owner, err
+}
+
+type offlineBackend struct {
+	utxos  map[ids.ID]*avax.UTXO
+	owners map[ids.ID]fx.Owner
+}
+
+func (b *offlineBackend) GetUTXO(_ context.Context, _, utxoID ids.ID) (*avax.UTXO, error) {
+	utxo, ok := b.utxos[utxoID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return utxo, nil
+}
+
+func (b *offlineBackend) GetOwner(_ context.Context, ownerID ids.ID) (fx.Owner, error) {
+	owner, ok := b.owners[ownerID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return owner, nil
+}
+
+// probeKeychain claims every key and records which ones were asked to sign
+type probeKeychain struct {
+	requested set.Set[ids.ShortID]
+}
+
+func (kc *probeKeychain) Get(addr ids.ShortID) (keychain.Signer, bool) {
+	return &probeSigner{kc: kc, addr: addr}, true
+}
+
+func (kc *probeKeychain) Addresses() set.Set[ids.ShortID] {
+	return kc.requested
+}
+
+type probeSigner struct {
+	kc   *probeKeychain
+	addr ids.ShortID
+}
+
+func (s *probeSigner) SignHash([]byte) ([]byte, error) {
+	s.kc.requested.Add(s.addr)
+	return make([]byte, secp256k1.SignatureLen), nil
+}
+
+func (s *probeSigner) Sign([]byte) ([]byte, error) {
+	return s.SignHash(nil)
+}
+
+func (s *probeSigner) Address() ids.ShortID {
+	return s.addr
+}
@@ -1,13 +1,45 @@
 package helpers
 
+import "path/filepath"
+
+// All paths below live in the data directory of the current workspace and
+// are updated by UseWorkspace
 var (
+	DataDir                      = "data/"
 	ValidatorManagerOwnerKeyPath = "data/validator_manager_owner_key.txt"
-	SubnetIdPath                 = "data/subnet_id.txt"
-	ChainIdPath                  = "data/chain_id.txt"
-	ConversionIdPath             = "data/conversion_id.txt"
-	InitializeValidatorSetTxPath = "data/initialize_validator_set_tx.txt"
-	L1GenesisPath                = "data/L1-genesis.json"
-	Node0KeysFolder              = "data/node0/staking/"
-
-	ExampleRewardCalculatorAddressPath = "data/example_reward_calculator_address.txt"
+	// Encrypted alternative to ValidatorManagerOwnerKeyPath, see OwnerKeyProvider
+	ValidatorManagerOwnerKeystorePath = "data/validator_manager_owner_key.json"
+	ManifestPath                      = "data/workspace.json"
+	L1GenesisPath                     = "data/L1-genesis.json"
+	// Optional overrides of generate-genesis, see GenesisSpec
+	GenesisSpecPath = "data/genesis-spec.yaml"
+	Node0KeysFolder = "data/node0/staking/"
+)
+
+const (
+	ownerKeyFileName      = "validator_manager_owner_key.txt"
+	ownerKeystoreFileName = "validator_manager_owner_key.json"
+	genesisFileName       = "L1-genesis.json"
+	genesisSpecFileName   = "genesis-spec.yaml"
+	node0KeysFolder       = "node0/staking"
 )
+
+// PendingTxPath is where a P-chain tx waiting for more signatures is kept
+func PendingTxPath(name string) string {
+	return filepath.Join(DataDir, name+"_tx.pending")
+}
+
+func setDataDir(dataDir string) {
+	DataDir = dataDir + "/"
+	ValidatorManagerOwnerKeyPath = filepath.Join(dataDir, ownerKeyFileName)
+	ValidatorManagerOwnerKeystorePath = filepath.Join(dataDir, ownerKeystoreFileName)
+	ManifestPath = filepath.Join(dataDir, manifestFileName)
+	L1GenesisPath = filepath.Join(dataDir, genesisFileName)
+	GenesisSpecPath = filepath.Join(dataDir, genesisSpecFileName)
+	Node0KeysFolder = filepath.Join(dataDir, node0KeysFolder) + "/"
+}
+
+// BalanceSamplesPath is where watch-balances keeps the last balances it saw
+func BalanceSamplesPath() string {
+	return filepath.Join(DataDir, "balance_samples.json")
+}
@@ -28,14 +28,6 @@ func FileExists(path string) (bool, error) {
 	return true, nil
 }
 
-// SaveId saves an ID to a file for the given type
-func SaveId(path string, id ids.ID) error {
-	if err := os.WriteFile(path, []byte(id.String()), 0644); err != nil {
-		return fmt.Errorf("saving ID to %s: %w", path, err)
-	}
-	return nil
-}
-
 // LoadId loads an ID from a file for the given type
 func LoadId(path string) (ids.ID, error) {
 	text, err := LoadText(path)
@@ -74,6 +66,50 @@ func SaveBytes(path string, value []byte) error {
 	return nil
 }
 
+// SaveBytesAtomic writes value to a temporary file next to path and renames it
+// into place, so readers never observe a partially written file
+func SaveBytesAtomic(path string, value []byte) error {
+	return saveBytesAtomic(path, value, 0644)
+}
+
+// SavePrivateBytes atomically writes value to a file only its owner can read
+func SavePrivateBytes(path string, value []byte) error {
+	return saveBytesAtomic(path, value, 0600)
+}
+
+func saveBytesAtomic(path string, value []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file for %s: %w", path, err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmpFile.Write(value); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("writing temporary file for %s: %w", path, err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("syncing temporary file for %s: %w", path, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("closing temporary file for %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("setting permissions on %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("renaming %s to %s: %w", tmpPath, path, err)
+	}
+	return nil
+}
+
 func LoadBytes(path string) ([]byte, error) {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
@@ -117,10 +153,6 @@ func LoadHex(path string) ([]byte, error) {
 	return bytes, nil
 }
 
-func SaveAddress(path string, address common.Address) error {
-	return SaveHex(path, address[:])
-}
-
 func LoadAddress(path string) (common.Address, error) {
 	bytes, err := LoadHex(path)
 	if err != nil {
@@ -149,7 +181,7 @@ func LoadNodeID(path string) (ids.NodeID, error) {
 }
 
 func SaveSecp256k1PrivateKey(path string, key *secp256k1.PrivateKey) error {
-	return SaveHex(path, key.Bytes())
+	return SavePrivateBytes(path, []byte(hex.EncodeToString(key.Bytes())))
 }
 
 func LoadSecp256k1PrivateKey(path string) (*secp256k1.PrivateKey, error) {
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Role is a responsibility that can be held by its own key or address. Roles
+// without a key or an address fall back to the validator manager owner key.
+type Role struct {
+	Name        string
+	Description string
+	// EVM roles are Ethereum addresses on the L1, the others P-chain addresses
+	EVM bool
+	// HD is where generate-keys --mnemonic derives the key of the role
+	HD HDKeyRole
+}
+
+var (
+	RoleFeePayer = Role{
+		Name:        "fee-payer",
+		Description: "pays P-chain fees and receives change",
+		HD:          HDKeyRole{Name: "fee payer", CoinType: AvalancheCoinType, Index: 0},
+	}
+	RoleSubnetOwner = Role{
+		Name:        "subnet-owner",
+		Description: "owns the subnet and authorizes create-chain and convert-to-L1",
+		HD:          HDKeyRole{Name: "subnet owner", CoinType: AvalancheCoinType, Index: 1},
+	}
+	RoleManagerOwner = Role{
+		Name:        "manager-owner",
+		Description: "owns the PoA validator manager and adds or removes validators",
+		EVM:         true,
+		HD:          HDKeyRole{Name: "manager owner", CoinType: EthereumCoinType, Index: 1},
+	}
+	RoleProxyAdminOwner = Role{
+		Name:        "proxy-admin-owner",
+		Description: "owns the ProxyAdmin that can upgrade the validator manager",
+		EVM:         true,
+		HD:          HDKeyRole{Name: "proxy admin owner", CoinType: EthereumCoinType, Index: 2},
+	}
+	RoleValidatorBalanceOwner = Role{
+		Name:        "validator-balance-owner",
+		Description: "receives the remaining balance of removed validators",
+		HD:          HDKeyRole{Name: "validator balance owner", CoinType: AvalancheCoinType, Index: 2},
+	}
+	RoleValidatorDisableOwner = Role{
+		Name:        "validator-disable-owner",
+		Description: "can disable validators on the P-chain",
+		HD:          HDKeyRole{Name: "validator disable owner", CoinType: AvalancheCoinType, Index: 3},
+	}
+
+	Roles = []Role{
+		RoleFeePayer,
+		RoleSubnetOwner,
+		RoleManagerOwner,
+		RoleProxyAdminOwner,
+		RoleValidatorBalanceOwner,
+		RoleValidatorDisableOwner,
+	}
+)
+
+// roleKeysFolder holds one key per role, next to the owner key
+const roleKeysFolder = "role_keys"
+
+func RoleByName(name string) (Role, error) {
+	names := make([]string, len(Roles))
+	for i, role := range Roles {
+		if role.Name == name {
+			return role, nil
+		}
+		names[i] = role.Name
+	}
+	return Role{}, fmt.Errorf("unknown role %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// RoleKeyProvider returns the provider of the key of role in the current
+// workspace. Like the owner key, an encrypted keystore takes precedence.
+func RoleKeyProvider(role Role) KeyProvider {
+	folder := filepath.Join(DataDir, roleKeysFolder)
+	encrypted := NewKeystoreKeyProvider(filepath.Join(folder, role.Name+".json"))
+	if exists, err := encrypted.Exists(); err == nil && exists {
+		return encrypted
+	}
+	return NewPlaintextKeyProvider(filepath.Join(folder, role.Name+".txt"))
+}
+
+// NewRoleKeyProvider is where a new key for role is saved
+func NewRoleKeyProvider(role Role, encrypt bool) (KeyProvider, error) {
+	folder := filepath.Join(DataDir, roleKeysFolder)
+	if err := os.MkdirAll(folder, 0700); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", folder, err)
+	}
+	if encrypt {
+		return NewKeystoreKeyProvider(filepath.Join(folder, role.Name+".json")), nil
+	}
+	return NewPlaintextKeyProvider(filepath.Join(folder, role.Name+".txt")), nil
+}
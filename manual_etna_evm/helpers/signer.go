@@ -0,0 +1,247 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer signs with a secp256k1 key that doesn't have to live in this process.
+// It is used for P-chain txs through SignerKeychain and for EVM txs through
+// NewSignerTransactor.
+type Signer interface {
+	// Address is the P-chain and X-chain address of the key
+	Address() ids.ShortID
+	// EthAddress is the EVM address of the key
+	EthAddress() common.Address
+	// SignHash returns a 65 byte [r || s || v] recoverable signature of a 32 byte hash
+	SignHash(hash []byte) ([]byte, error)
+}
+
+type localSigner struct {
+	key *secp256k1.PrivateKey
+}
+
+// NewLocalSigner signs with a key held in memory
+func NewLocalSigner(key *secp256k1.PrivateKey) Signer {
+	return &localSigner{key: key}
+}
+
+func (s *localSigner) Address() ids.ShortID {
+	return s.key.Address()
+}
+
+func (s *localSigner) EthAddress() common.Address {
+	return crypto.PubkeyToAddress(s.key.ToECDSA().PublicKey)
+}
+
+func (s *localSigner) SignHash(hash []byte) ([]byte, error) {
+	return s.key.SignHash(hash)
+}
+
+// SignerAccount is returned by the signer_account method of an external signer
+type SignerAccount struct {
+	Address    ids.ShortID    `json:"address"`
+	EthAddress common.Address `json:"ethAddress"`
+}
+
+// externalSigner forwards every signature to a signer service over a unix
+// socket. The service speaks JSON-RPC 2.0 with the methods of SignerService.
+type externalSigner struct {
+	client  *rpc.Client
+	account SignerAccount
+}
+
+// NewExternalSigner connects to the signer service listening on socketPath
+func NewExternalSigner(socketPath string) (Signer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := rpc.DialIPC(ctx, socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to signer at %s: %w", socketPath, err)
+	}
+	signer := &externalSigner{client: client}
+	if err := client.CallContext(ctx, &signer.account, "signer_account"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("getting account from signer at %s: %w", socketPath, err)
+	}
+	return signer, nil
+}
+
+func (s *externalSigner) Address() ids.ShortID {
+	return s.account.Address
+}
+
+func (s *externalSigner) EthAddress() common.Address {
+	return s.account.EthAddress
+}
+
+func (s *externalSigner) SignHash(hash []byte) ([]byte, error) {
+	// Signing may wait for someone to approve it on the other end
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var signature hexutil.Bytes
+	if err := s.client.CallContext(ctx, &signature, "signer_signHash", hexutil.Bytes(hash)); err != nil {
+		return nil, fmt.Errorf("signing with external signer: %w", err)
+	}
+	if len(signature) != secp256k1.SignatureLen {
+		return nil, fmt.Errorf("external signer returned a %d byte signature, expected %d", len(signature), secp256k1.SignatureLen)
+	}
+
+	// Never trust the other end to have signed with the key it claims
+	pubKey, err := secp256k1.RecoverPublicKeyFromHash(hash, signature)
+	if err != nil {
+		return nil, fmt.Errorf("recovering public key from external signature: %w", err)
+	}
+	if pubKey.Address() != s.account.Address {
+		return nil, fmt.Errorf("external signer signed with %s instead of %s", pubKey.Address(), s.account.Address)
+	}
+	return signature, nil
+}
+
+// SignerService exposes a Signer over JSON-RPC as the "signer" namespace
+type SignerService struct {
+	signer Signer
+	log    func(format string, args ...interface{})
+}
+
+func NewSignerService(signer Signer, log func(format string, args ...interface{})) *SignerService {
+	return &SignerService{signer: signer, log: log}
+}
+
+func (s *SignerService) Account() SignerAccount {
+	return SignerAccount{Address: s.signer.Address(), EthAddress: s.signer.EthAddress()}
+}
+
+func (s *SignerService) SignHash(hash hexutil.Bytes) (hexutil.Bytes, error) {
+	if len(hash) != hashing.HashLen {
+		return nil, fmt.Errorf("expected a %d byte hash, got %d bytes", hashing.HashLen, len(hash))
+	}
+	s.log("Signing hash %s\n", hash)
+	return s.signer.SignHash(hash)
+}
+
+// ServeSigner serves signer on a unix socket readable by the current user only, until the listener fails
+func ServeSigner(socketPath string, service *SignerService) error {
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing stale socket %s: %w", socketPath, err)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", socketPath, err)
+	}
+	defer os.Remove(socketPath)
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		return fmt.Errorf("setting permissions on %s: %w", socketPath, err)
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("signer", service); err != nil {
+		return fmt.Errorf("registering signer service: %w", err)
+	}
+	return server.ServeListener(listener)
+}
+
+// SignerKeychain lets the avalanchego wallet sign with Signers on every chain
+type SignerKeychain struct {
+	signers []Signer
+}
+
+// NewSignerKeychain adapts Signers for primary.WalletConfig, as both
+// AVAXKeychain and EthKeychain. The same key may be passed more than once.
+func NewSignerKeychain(signers ...Signer) *SignerKeychain {
+	return &SignerKeychain{signers: signers}
+}
+
+func (kc *SignerKeychain) Get(addr ids.ShortID) (keychain.Signer, bool) {
+	for _, signer := range kc.signers {
+		if addr == signer.Address() {
+			return &keychainSigner{signer: signer}, true
+		}
+	}
+	return nil, false
+}
+
+func (kc *SignerKeychain) Addresses() set.Set[ids.ShortID] {
+	addrs := set.NewSet[ids.ShortID](len(kc.signers))
+	for _, signer := range kc.signers {
+		addrs.Add(signer.Address())
+	}
+	return addrs
+}
+
+func (kc *SignerKeychain) GetEth(addr common.Address) (keychain.Signer, bool) {
+	for _, signer := range kc.signers {
+		if addr == signer.EthAddress() {
+			return &keychainSigner{signer: signer}, true
+		}
+	}
+	return nil, false
+}
+
+func (kc *SignerKeychain) EthAddresses() set.Set[common.Address] {
+	addrs := set.NewSet[common.Address](len(kc.signers))
+	for _, signer := range kc.signers {
+		addrs.Add(signer.EthAddress())
+	}
+	return addrs
+}
+
+type keychainSigner struct {
+	signer Signer
+}
+
+func (s *keychainSigner) SignHash(hash []byte) ([]byte, error) {
+	return s.signer.SignHash(hash)
+}
+
+func (s *keychainSigner) Sign(msg []byte) ([]byte, error) {
+	return s.signer.SignHash(hashing.ComputeHash256(msg))
+}
+
+func (s *keychainSigner) Address() ids.ShortID {
+	return s.signer.Address()
+}
+
+// NewSignerTransactor is bind.NewKeyedTransactorWithChainID for a Signer
+func NewSignerTransactor(signer Signer, chainID *big.Int) *bind.TransactOpts {
+	txSigner := types.LatestSignerForChainID(chainID)
+	return &bind.TransactOpts{
+		From: signer.EthAddress(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != signer.EthAddress() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return SignEVMTx(signer, txSigner, tx)
+		},
+		Context: context.Background(),
+	}
+}
+
+// SignEVMTx signs an EVM transaction with a Signer
+func SignEVMTx(signer Signer, txSigner types.Signer, tx *types.Transaction) (*types.Transaction, error) {
+	hash := txSigner.Hash(tx)
+	signature, err := signer.SignHash(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(txSigner, signature)
+}
@@ -0,0 +1,318 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+const (
+	// DefaultWorkspace keeps its state in data/ so checkouts from before
+	// workspaces existed keep working
+	DefaultWorkspace = "default"
+	// WorkspaceEnvVar selects the workspace when --workspace is not passed
+	WorkspaceEnvVar = "ETNA_WORKSPACE"
+	// WorkspacesDir holds the data directories of all other workspaces
+	WorkspacesDir = "workspaces"
+
+	DefaultNodeHTTPPort = 9650
+	// Every workspace reserves this many ports, two per node
+	workspacePortRange = 200
+)
+
+var (
+	CurrentWorkspace = DefaultWorkspace
+
+	activeWorkspacePath = filepath.Join(WorkspacesDir, "active_workspace.txt")
+	workspaceNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+func ValidateWorkspaceName(name string) error {
+	if !workspaceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid workspace name %q: use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// WorkspaceDataDir returns the directory holding all state of a workspace
+func WorkspaceDataDir(name string) string {
+	if name == DefaultWorkspace {
+		return "data"
+	}
+	return filepath.Join(WorkspacesDir, name)
+}
+
+// UseWorkspace points every persisted path at the data directory of the named workspace
+func UseWorkspace(name string) error {
+	if err := ValidateWorkspaceName(name); err != nil {
+		return err
+	}
+	CurrentWorkspace = name
+	setDataDir(WorkspaceDataDir(name))
+	return nil
+}
+
+func WorkspaceExists(name string) (bool, error) {
+	if name == DefaultWorkspace {
+		return true, nil
+	}
+	return FileExists(WorkspaceDataDir(name))
+}
+
+// ActiveWorkspace returns the workspace selected with `workspace switch`
+func ActiveWorkspace() (string, error) {
+	exists, err := FileExists(activeWorkspacePath)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return DefaultWorkspace, nil
+	}
+	return LoadText(activeWorkspacePath)
+}
+
+func SetActiveWorkspace(name string) error {
+	return SaveText(activeWorkspacePath, name)
+}
+
+// ListWorkspaces returns the names of all workspaces, the default one first
+func ListWorkspaces() ([]string, error) {
+	names := []string{}
+	entries, err := os.ReadDir(WorkspacesDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", WorkspacesDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateWorkspaceName(entry.Name()) == nil && entry.Name() != DefaultWorkspace {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultWorkspace}, names...), nil
+}
+
+// CreateWorkspace creates an empty workspace with its own node port range
+func CreateWorkspace(name string, baseHTTPPort int) error {
+	if err := ValidateWorkspaceName(name); err != nil {
+		return err
+	}
+	exists, err := WorkspaceExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("workspace %s already exists", name)
+	}
+
+	if baseHTTPPort == 0 {
+		baseHTTPPort, err = nextFreeBaseHTTPPort()
+		if err != nil {
+			return err
+		}
+	}
+
+	manifest := &Manifest{BaseHTTPPort: baseHTTPPort, Validators: []ManifestValidator{}}
+	return writeManifest(filepath.Join(WorkspaceDataDir(name), manifestFileName), manifest)
+}
+
+// DeleteWorkspace removes all state of a workspace, including its keys
+func DeleteWorkspace(name string) error {
+	if name == DefaultWorkspace {
+		return errors.New("the default workspace can't be deleted")
+	}
+	exists, err := WorkspaceExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("workspace %s does not exist", name)
+	}
+	if err := os.RemoveAll(WorkspaceDataDir(name)); err != nil {
+		return fmt.Errorf("removing workspace %s: %w", name, err)
+	}
+
+	active, err := ActiveWorkspace()
+	if err != nil {
+		return err
+	}
+	if active == name {
+		return SetActiveWorkspace(DefaultWorkspace)
+	}
+	return nil
+}
+
+// WorkspaceHasKeys reports whether a workspace holds the owner key or node0 credentials
+func WorkspaceHasKeys(name string) (bool, error) {
+	dir := WorkspaceDataDir(name)
+	for _, path := range []string{filepath.Join(dir, ownerKeyFileName), filepath.Join(dir, ownerKeystoreFileName), filepath.Join(dir, node0KeysFolder)} {
+		exists, err := FileExists(path)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// LoadWorkspaceManifest reads the manifest of any workspace without switching to it
+func LoadWorkspaceManifest(name string) (*Manifest, error) {
+	return readManifest(filepath.Join(WorkspaceDataDir(name), manifestFileName))
+}
+
+func nextFreeBaseHTTPPort() (int, error) {
+	names, err := ListWorkspaces()
+	if err != nil {
+		return 0, err
+	}
+	used := map[int]bool{}
+	for _, name := range names {
+		manifest, err := LoadWorkspaceManifest(name)
+		if err != nil {
+			return 0, err
+		}
+		used[manifest.NodeBaseHTTPPort()] = true
+	}
+	for port := DefaultNodeHTTPPort; ; port += workspacePortRange {
+		if !used[port] {
+			return port, nil
+		}
+	}
+}
+
+// ContainerName is the docker container name of the nth node of the current workspace
+func ContainerName(nodeIndex int) string {
+	if CurrentWorkspace == DefaultWorkspace {
+		return fmt.Sprintf("node%d", nodeIndex)
+	}
+	return fmt.Sprintf("%s-node%d", CurrentWorkspace, nodeIndex)
+}
+
+// ComposeProjectName is the docker compose project that runs node0 of the current workspace
+func ComposeProjectName() string {
+	if CurrentWorkspace == DefaultWorkspace {
+		return "node"
+	}
+	return "node-" + CurrentWorkspace
+}
+
+// NodeHTTPPort is the HTTP port of the nth node of the current workspace; its
+// staking port is the one right after it
+func NodeHTTPPort(nodeIndex int) (int, error) {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return 0, err
+	}
+	return manifest.NodeBaseHTTPPort() + nodeIndex*2, nil
+}
+
+// NodeURI is the API endpoint of the nth node of the current workspace
+func NodeURI(nodeIndex int) (string, error) {
+	port, err := NodeHTTPPort(nodeIndex)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", port), nil
+}
+
+// NodeRPCURL is the EVM RPC endpoint of the L1 chain on the nth node of the current workspace
+func NodeRPCURL(nodeIndex int, chainID ids.ID) (string, error) {
+	uri, err := NodeURI(nodeIndex)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/ext/bc/%s/rpc", uri, chainID), nil
+}
+
+// ContainerLabel is set to the workspace name on every container a workspace starts
+const ContainerLabel = "etna.workspace"
+
+// ContainerLabelFilter selects the containers of a workspace in `docker ps`
+func ContainerLabelFilter(name string) string {
+	return fmt.Sprintf("label=%s=%s", ContainerLabel, name)
+}
+
+// ResetWorkspaceData deletes the persisted state of the current workspace and
+// returns the removed paths. The manifest is replaced by an empty one keeping
+// the node port range, and the hand written genesis spec is kept. Validator
+// credentials and the owner key are kept if keepKeys is set, the avalanchego
+// database of node0 if keepNodeDB is set.
+func ResetWorkspaceData(keepKeys bool, keepNodeDB bool) ([]string, error) {
+	manifest, err := LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir := filepath.Clean(DataDir)
+	entries, err := os.ReadDir(dataDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", dataDir, err)
+	}
+
+	removed := []string{}
+	remove := func(path string) error {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("removing %s: %w", path, err)
+		}
+		removed = append(removed, path)
+		return nil
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dataDir, entry.Name())
+		switch {
+		case entry.Name() == manifestFileName, entry.Name() == genesisSpecFileName:
+			continue
+		case entry.Name() == filepath.Dir(node0KeysFolder):
+			// node0/ holds both the staking keys and the node database
+			if !keepKeys && !keepNodeDB {
+				if err := remove(path); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			nodeEntries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", path, err)
+			}
+			for _, nodeEntry := range nodeEntries {
+				isKeys := nodeEntry.Name() == filepath.Base(node0KeysFolder)
+				if (isKeys && keepKeys) || (!isKeys && keepNodeDB) {
+					continue
+				}
+				if err := remove(filepath.Join(path, nodeEntry.Name())); err != nil {
+					return nil, err
+				}
+			}
+		case isKeyFile(entry.Name()):
+			if keepKeys {
+				continue
+			}
+			if err := remove(path); err != nil {
+				return nil, err
+			}
+		default:
+			if err := remove(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fresh := &Manifest{BaseHTTPPort: manifest.BaseHTTPPort, Validators: []ManifestValidator{}}
+	if keepKeys {
+		fresh.Roles = manifest.Roles
+	}
+	if err := SaveManifest(fresh); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// isKeyFile reports whether an entry of the data directory holds key material
+func isKeyFile(name string) bool {
+	return name == ownerKeyFileName || name == ownerKeystoreFileName || name == roleKeysFolder || strings.HasPrefix(name, addValidatorFolderPrefix)
+}